@@ -0,0 +1,76 @@
+// cmd/importer/main.go
+package main
+
+import (
+    "database/sql"
+    "fmt"
+    "log"
+    "os"
+    "path/filepath"
+
+    _ "github.com/lib/pq"
+
+    "github.com/unclebandit/smsleopard-backend/internal/phone"
+    "github.com/unclebandit/smsleopard-backend/internal/repository"
+    "github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+// Imports a customer CSV from the command line, e.g.
+//
+//     go run ./cmd/importer customers.csv
+//
+// The file is streamed with the same rules as POST /customers/import and the
+// rejected rows of the report are printed.
+func main() {
+    if len(os.Args) != 2 {
+        fmt.Fprintln(os.Stderr, "usage: importer <customers.csv>")
+        os.Exit(2)
+    }
+
+    db, err := sql.Open("postgres", os.Getenv("DATABASE_URL"))
+    if err != nil {
+        log.Fatal(err)
+    }
+    defer db.Close()
+
+    file, err := os.Open(os.Args[1])
+    if err != nil {
+        log.Fatal(err)
+    }
+    defer file.Close()
+
+    phones, err := phone.ForCountry(os.Getenv("PHONE_COUNTRY"))
+    if err != nil {
+        log.Fatal(err)
+    }
+    svc := &service.CustomerImportService{
+        Jobs:   &repository.ImportJobRepository{DB: db},
+        Phones: phones,
+    }
+
+    job, err := svc.Import(file, filepath.Base(os.Args[1]))
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    fmt.Printf("Import job %d %s: %d rows, %d accepted, %d updated, %d rejected\n",
+        job.ID, job.Status, job.TotalRows, job.Accepted, job.Updated, job.Rejected)
+    if job.Error != "" {
+        fmt.Println("Error:", job.Error)
+    }
+
+    for page, pages := 1, 1; page <= pages; page++ {
+        rows, pagination, err := svc.Rows(job.ID, "rejected", page, 1000)
+        if err != nil {
+            log.Fatal(err)
+        }
+        for _, row := range rows {
+            fmt.Printf("  row %d (%s): %s\n", row.RowNumber, row.Phone, row.Reason)
+        }
+        pages = pagination["total_pages"]
+    }
+
+    if job.Status != "completed" {
+        os.Exit(1)
+    }
+}
@@ -0,0 +1,62 @@
+// cmd/phone-backfill/main.go
+package main
+
+import (
+    "database/sql"
+    "flag"
+    "fmt"
+    "log"
+    "os"
+
+    _ "github.com/lib/pq"
+
+    "github.com/unclebandit/smsleopard-backend/internal/phone"
+    "github.com/unclebandit/smsleopard-backend/internal/repository"
+    "github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+// Converts the phones of existing customers to E.164, e.g.
+//
+//     go run ./cmd/phone-backfill -dry-run
+//
+// Local numbers are read as PHONE_COUNTRY (default KE). Customers whose phones turn
+// out to be the same number are listed and left for a manual merge.
+func main() {
+    dryRun := flag.Bool("dry-run", false, "report changes without writing them")
+    flag.Parse()
+
+    db, err := sql.Open("postgres", os.Getenv("DATABASE_URL"))
+    if err != nil {
+        log.Fatal(err)
+    }
+    defer db.Close()
+
+    phones, err := phone.ForCountry(os.Getenv("PHONE_COUNTRY"))
+    if err != nil {
+        log.Fatal(err)
+    }
+    svc := &service.CustomerService{
+        Repo:   &repository.CustomerRepository{DB: db},
+        Phones: phones,
+    }
+
+    report, err := svc.NormalizePhones(*dryRun)
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    verb := "updated"
+    if *dryRun {
+        verb = "would update"
+    }
+    fmt.Printf("checked %d customers, %s %d\n", report.Checked, verb, report.Updated)
+    for _, issue := range report.Invalid {
+        fmt.Printf("invalid: customer %d %q: %s\n", issue.CustomerID, issue.Phone, issue.Reason)
+    }
+    for _, dup := range report.Duplicates {
+        fmt.Printf("duplicate: %s is shared by customers %v\n", dup.Phone, dup.CustomerIDs)
+    }
+    if len(report.Duplicates) > 0 {
+        os.Exit(1)
+    }
+}
@@ -22,6 +22,7 @@ func main() {
     seedFiles := []string{
         "seed/customers.sql",
         "seed/campaigns.sql",
+        "seed/consents.sql",
     }
 
     for _, file := range seedFiles {
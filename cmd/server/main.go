@@ -4,12 +4,16 @@ package main
 import (
 	"log"
 	"net/http"
+	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/go-chi/chi/v5"
 
 	"github.com/unclebandit/smsleopard-backend/internal/controller"
 	"github.com/unclebandit/smsleopard-backend/internal/db"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/phone"
 	"github.com/unclebandit/smsleopard-backend/internal/repository"
 	"github.com/unclebandit/smsleopard-backend/internal/service"
     "github.com/unclebandit/smsleopard-backend/internal/queue"
@@ -30,15 +34,100 @@ func main() {
 	customerRepo := &repository.CustomerRepository{DB: db.DB}
 	campaignRepo := &repository.CampaignRepository{DB: db.DB}
     outboundRepo := &repository.OutboundMessageRepository{DB: db.DB}
-    queue.StartCampaignSendSubscriber(q, campaignRepo)
+	whatsappTemplateRepo := &repository.WhatsAppTemplateRepository{DB: db.DB}
+	shortLinkRepo := &repository.ShortLinkRepository{DB: db.DB}
+	segmentRepo := &repository.SegmentRepository{DB: db.DB}
+	suppressionRepo := &repository.SuppressionRepository{DB: db.DB}
+	consentRepo := &repository.ConsentRepository{DB: db.DB}
+
+	// local phone numbers are read as this country's format
+	phones, err := phone.ForCountry(os.Getenv("PHONE_COUNTRY"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// e.g. FREQUENCY_CAP=2 messages per FREQUENCY_CAP_WINDOW=24h per channel; unset disables capping
+	frequencyCap, err := service.ParseFrequencyCap(os.Getenv("FREQUENCY_CAP"), os.Getenv("FREQUENCY_CAP_WINDOW"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// e.g. QUIET_HOURS=21:00-08:00 in the customer's timezone, DEFAULT_TIMEZONE for customers without one
+	quietHours, err := service.ParseQuietHours(os.Getenv("QUIET_HOURS"), os.Getenv("DEFAULT_TIMEZONE"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// sending campaigns finish as failed when more than CAMPAIGN_FAILURE_THRESHOLD (e.g. 0.5 or 50%) of attempted messages failed
+	completion, err := service.ParseFailureThreshold(os.Getenv("CAMPAIGN_FAILURE_THRESHOLD"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	completion.Events = q
+	q.Subscribe("campaign_completed", func(payload any) error {
+		log.Printf("📣 campaign_completed: %+v\n", payload)
+		return nil
+	})
+
+	dispatchService := &service.DispatchService{
+		CampaignRepo:    campaignRepo,
+		SuppressionRepo: suppressionRepo,
+		FrequencyCap:    frequencyCap,
+		QuietHours:      quietHours,
+		CustomerRepo:    customerRepo,
+		Completion:      completion,
+		Send: func(msg *model.OutboundMessage) error {
+			return queue.MockSender(msg.RenderedContent)
+		},
+		Queue: q,
+	}
+    queue.StartCampaignSendSubscriber(q, dispatchService.Deliver)
+
+	// requeue messages deferred by the frequency cap or held for quiet hours once they are due
+	go func() {
+		for range time.Tick(time.Minute) {
+			if n, err := dispatchService.ReleaseDue(); err != nil {
+				log.Println("⚠️ Failed to release held messages:", err)
+			} else if n > 0 {
+				log.Println("⏰ Released", n, "held messages")
+			}
+		}
+	}()
 
 	campaignService := &service.CampaignService{
 		CampaignRepo: campaignRepo,
 		CustomerRepo: customerRepo,
         OutboundRepo: outboundRepo,
         Queue:        q,  
+
+		WhatsAppTemplateRepo: whatsappTemplateRepo,
+		LinkRepo:             shortLinkRepo,
+		LinkBaseURL:          os.Getenv("LINK_BASE_URL"),
+		SegmentRepo:          segmentRepo,
+		SuppressionRepo:      suppressionRepo,
+		ConsentRepo:          consentRepo,
+		FrequencyCap:         frequencyCap,
+		Completion:           completion,
+	}
+	if campaignService.LinkBaseURL == "" {
+		campaignService.LinkBaseURL = "http://localhost:8080"
 	}
 
+	// send scheduled campaigns once due; the advisory lock lets every replica run this loop
+	scheduler := &service.CampaignScheduler{
+		Repo:      &repository.CampaignScheduleRepository{DB: db.DB},
+		Campaigns: campaignService,
+	}
+	go func() {
+		for now := range time.Tick(time.Minute) {
+			if n, err := scheduler.Tick(now); err != nil {
+				log.Println("⚠️ Scheduler pass failed:", err)
+			} else if n > 0 {
+				log.Println("📅 Dispatched", n, "scheduled campaigns")
+			}
+		}
+	}()
+
 	campaignController := &controller.CampaignController{
 		CampaignService: campaignService,
 	}
@@ -48,7 +137,31 @@ func main() {
 	Service: campaignService, 
     }
 
-    
+    templateController := &controller.TemplateController{}
+	whatsappTemplateController := &controller.WhatsAppTemplateController{
+		Service: &service.WhatsAppTemplateService{Repo: whatsappTemplateRepo},
+	}
+	customerController := &controller.CustomerController{
+		Service: &service.CustomerService{Repo: customerRepo, Phones: phones},
+	}
+	customerImportController := &controller.CustomerImportController{
+		Service: &service.CustomerImportService{
+			Jobs:   &repository.ImportJobRepository{DB: db.DB},
+			Phones: phones,
+		},
+	}
+	segmentController := &controller.SegmentController{
+		Service: &service.SegmentService{Repo: segmentRepo},
+	}
+	linkController := &controller.LinkController{
+		Service: &service.LinkService{Repo: shortLinkRepo},
+	}
+	consentController := &controller.ConsentController{
+		Service: &service.ConsentService{Repo: consentRepo},
+	}
+	suppressionController := &controller.SuppressionController{
+		Service: &service.SuppressionService{Repo: suppressionRepo},
+	}
 
 
 	r := chi.NewRouter()
@@ -56,11 +169,56 @@ func main() {
 	// Campaign routes
 	r.Post("/campaigns", campaignController.CreateCampaign)
 	r.Get("/campaigns", campaignController.ListCampaigns)
+	r.Patch("/campaigns/{id}", campaignController.UpdateCampaign)
+	r.Delete("/campaigns/{id}", campaignController.DeleteCampaign)
 	//r.Get("/campaigns/{id}", campaignController.GetCampaignDetails)
 	r.Post("/campaigns/{id}/send", campaignController.SendCampaign)
+	r.Post("/campaigns/{id}/pause", campaignController.PauseCampaign)
+	r.Post("/campaigns/{id}/resume", campaignController.ResumeCampaign)
+	r.Post("/campaigns/{id}/cancel", campaignController.CancelCampaign)
+	r.Post("/campaigns/{id}/clone", campaignController.CloneCampaign)
 	r.Post("/campaigns/{id}/personalized-preview", campaignController.PersonalizedPreview)
     r.Get("/campaigns/{id}", campaignHandler.GetCampaignHandlerWithStats)
 
+	// Template routes
+	r.Post("/templates/validate", templateController.Validate)
+
+	// WhatsApp template registry
+	r.Post("/whatsapp-templates", whatsappTemplateController.Create)
+	r.Get("/whatsapp-templates", whatsappTemplateController.List)
+	r.Get("/whatsapp-templates/{id}", whatsappTemplateController.Get)
+
+	// Customer routes
+	r.Post("/customers", customerController.Create)
+	r.Get("/customers", customerController.List)
+	r.Post("/customers/import", customerImportController.Import)
+	r.Get("/customers/imports/{id}", customerImportController.Get)
+	r.Get("/customers/imports/{id}/rows", customerImportController.Rows)
+	r.Get("/customers/{id}", customerController.Get)
+	r.Patch("/customers/{id}", customerController.Update)
+	r.Delete("/customers/{id}", customerController.Delete)
+	r.Get("/customers/{id}/export", customerController.Export)
+	r.Post("/customers/{id}/tags", customerController.AddTags)
+	r.Get("/customers/{id}/tags", customerController.Tags)
+	r.Delete("/customers/{id}/tags/{tag}", customerController.RemoveTag)
+	r.Post("/customers/{id}/consents", consentController.Grant)
+	r.Get("/customers/{id}/consents", consentController.List)
+	r.Post("/customers/{id}/consents/{channel}/revoke", consentController.Revoke)
+
+	// Audience segments
+	r.Post("/segments", segmentController.Create)
+	r.Get("/segments", segmentController.List)
+	r.Post("/segments/preview", segmentController.Preview)
+	r.Get("/segments/{id}", segmentController.Get)
+
+	// Tracked short links
+	r.Get("/l/{code}", linkController.Redirect)
+
+	// Opt-outs
+	r.Post("/suppressions", suppressionController.Create)
+	r.Get("/suppressions", suppressionController.List)
+	r.Delete("/suppressions/{id}", suppressionController.Delete)
+
 
 	log.Println("🚀 Server running on :8080")
 	log.Fatal(http.ListenAndServe(":8080", r))
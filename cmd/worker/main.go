@@ -6,14 +6,14 @@ import (
 	"fmt"
 	"log"
 	"math/rand"
-	"strings"
+	"os"
 	"time"
 
 	_ "github.com/lib/pq"
 	"github.com/streadway/amqp"
-	"github.com/unclebandit/smsleopard-backend/internal/model"
 	"github.com/unclebandit/smsleopard-backend/internal/repository"
 	"github.com/unclebandit/smsleopard-backend/internal/service"
+	"github.com/unclebandit/smsleopard-backend/internal/sms"
 )
 
 type QueueJob struct {
@@ -31,11 +31,40 @@ func main() {
     customerRepo := &repository.CustomerRepository{DB: db}
     campaignRepo := &repository.CampaignRepository{DB: db}
     outboundRepo := &repository.OutboundMessageRepository{DB: db}
+    suppressionRepo := &repository.SuppressionRepository{DB: db}
 
     campaignService := &service.CampaignService{
         CampaignRepo:  campaignRepo,
         CustomerRepo:  customerRepo,
         OutboundRepo:  outboundRepo,
+
+        WhatsAppTemplateRepo: &repository.WhatsAppTemplateRepository{DB: db},
+        LinkRepo:             &repository.ShortLinkRepository{DB: db},
+        LinkBaseURL:          os.Getenv("LINK_BASE_URL"),
+        SuppressionRepo:      suppressionRepo,
+    }
+    if campaignService.LinkBaseURL == "" {
+        campaignService.LinkBaseURL = "http://localhost:8080"
+    }
+    frequencyCap, err := service.ParseFrequencyCap(os.Getenv("FREQUENCY_CAP"), os.Getenv("FREQUENCY_CAP_WINDOW"))
+    if err != nil {
+        log.Fatal(err)
+    }
+    quietHours, err := service.ParseQuietHours(os.Getenv("QUIET_HOURS"), os.Getenv("DEFAULT_TIMEZONE"))
+    if err != nil {
+        log.Fatal(err)
+    }
+    completion, err := service.ParseFailureThreshold(os.Getenv("CAMPAIGN_FAILURE_THRESHOLD"))
+    if err != nil {
+        log.Fatal(err)
+    }
+    dispatchService := &service.DispatchService{
+        CampaignRepo:    campaignRepo,
+        SuppressionRepo: suppressionRepo,
+        FrequencyCap:    frequencyCap,
+        QuietHours:      quietHours,
+        CustomerRepo:    customerRepo,
+        Completion:      completion,
     }
 
     // Connect to RabbitMQ
@@ -50,6 +79,8 @@ func main() {
         log.Fatal("Failed to open a channel:", err)
     }
     defer ch.Close()
+    completion.Events = amqpEvents{ch}
+    dispatchService.Queue = amqpSends{ch}
 
     q, err := ch.QueueDeclare(
         "campaign_sends", // name
@@ -88,7 +119,7 @@ func main() {
             }
 
             // Process the message
-            err := processMessage(job.OutboundMessageID, campaignService)
+            err := processMessage(job.OutboundMessageID, campaignService, dispatchService)
             if err != nil {
                 log.Println("Failed to send message:", err)
                 // Retry logic: requeue up to 3 times
@@ -106,16 +137,76 @@ func main() {
         }
     }()
 
+    // requeue messages deferred by the frequency cap or held for quiet hours once they are due,
+    // so the worker does not depend on the server running alongside it
+    go func() {
+        for range time.Tick(time.Minute) {
+            if n, err := dispatchService.ReleaseDue(); err != nil {
+                log.Println("⚠️ Failed to release held messages:", err)
+            } else if n > 0 {
+                log.Println("⏰ Released", n, "held messages")
+            }
+        }
+    }()
+
     log.Println("Worker running, waiting for messages...")
     <-forever
 }
 
-func processMessage(outboundID int, svc *service.CampaignService) error {
+// amqpSends publishes outbound message IDs to a send queue in the job format the worker consumes
+type amqpSends struct {
+    ch *amqp.Channel
+}
+
+func (s amqpSends) Publish(topic string, payload any) error {
+    id, ok := payload.(int)
+    if !ok {
+        return fmt.Errorf("%s expects an outbound message ID, got %T", topic, payload)
+    }
+    body, err := json.Marshal(QueueJob{OutboundMessageID: id})
+    if err != nil {
+        return err
+    }
+    return s.ch.Publish("", topic, false, false, amqp.Publishing{ContentType: "application/json", Body: body})
+}
+
+func (s amqpSends) Subscribe(topic string, handler func(payload any) error) error {
+    return fmt.Errorf("the worker consumes %s directly", topic)
+}
+
+// amqpEvents publishes events as JSON to the RabbitMQ queue named after their topic
+type amqpEvents struct {
+    ch *amqp.Channel
+}
+
+func (e amqpEvents) Publish(topic string, payload any) error {
+    body, err := json.Marshal(payload)
+    if err != nil {
+        return err
+    }
+    if _, err := e.ch.QueueDeclare(topic, true, false, false, false, nil); err != nil {
+        return err
+    }
+    return e.ch.Publish("", topic, false, false, amqp.Publishing{ContentType: "application/json", Body: body})
+}
+
+func (e amqpEvents) Subscribe(topic string, handler func(payload any) error) error {
+    return fmt.Errorf("events are only published from the worker")
+}
+
+func processMessage(outboundID int, svc *service.CampaignService, dispatch *service.DispatchService) error {
     // Fetch outbound message + customer + campaign
     msg, err := svc.OutboundRepo.GetByID(outboundID)
     if err != nil {
         return err
     }
+    if msg.Status != "pending" && msg.Status != "retrying" {
+        return nil // already handled, e.g. a requeued job
+    }
+    // a message queued twice, e.g. by a resumed dispatch, is only delivered by whoever claims it
+    if claimed, err := dispatch.CampaignRepo.ClaimOutboundMessage(msg.ID); err != nil || !claimed {
+        return err
+    }
 
     customer, err := svc.CustomerRepo.GetByID(msg.CustomerID)
     if err != nil {
@@ -127,49 +218,46 @@ func processMessage(outboundID int, svc *service.CampaignService) error {
         return err
     }
 
-    // Render message
-    rendered := renderTemplate(campaign.BaseTemplate, customer)
+    // Opted-out, capped or sleeping customers are recorded, not messaged now
+    if held, err := dispatch.Screen(msg, campaign); err != nil || held {
+        if err == nil {
+            dispatch.CheckCompletion(campaign.ID)
+        }
+        return err
+    }
+
+    // Render message with the same engine used by preview and SendCampaign
+    if msg.RenderedContent == "" {
+        if err := svc.RenderOutbound(campaign, customer, msg); err != nil {
+            return err
+        }
+    }
+    rendered := msg.RenderedContent
 
     // Mock sending
     success := mockSend(rendered)
     if success {
         msg.Status = "sent"
         msg.RenderedContent = rendered
+        msg.Segments = 0 // only SMS is billed per segment
+        if campaign.Channel == "sms" {
+            msg.Segments = sms.Analyze(rendered).Segments
+        }
         msg.LastError = ""
     } else {
-        msg.Status = "failed"
+        msg.Status = service.FailureStatus(msg.RetryCount)
         msg.LastError = "mock send failed"
         msg.RetryCount += 1
     }
 
-    return svc.OutboundRepo.Update(msg)
-}
-
-func renderTemplate(template string, customer *model.Customer) string {
-    result := template
-    placeholders := map[string]string{
-        "first_name":       customer.FirstName,
-        "last_name":        customer.LastName,
-        "location":         customer.Location,
-        "preferred_product": customer.PreferredProduct,
-    }
-    for k, v := range placeholders {
-        result = replacePlaceholder(result, k, v)
+    if err := svc.OutboundRepo.Update(msg); err != nil {
+        return err
     }
-    return result
-}
-
-func replacePlaceholder(template, key, value string) string {
-    placeholder := "{" + key + "}"
-    if value == "" {
-        value = "N/A"
+    if msg.Status == "retrying" {
+        return fmt.Errorf("message %d: %s", msg.ID, msg.LastError) // requeued
     }
-    return stringReplace(template, placeholder, value)
-}
-
-// Simple string replace helper
-func stringReplace(s, old, new string) string {
-    return fmt.Sprintf("%s", strings.ReplaceAll(s, old, new))
+    dispatch.CheckCompletion(campaign.ID)
+    return nil
 }
 
 // Mock sender: 90% chance of success
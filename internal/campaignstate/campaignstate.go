@@ -0,0 +1,55 @@
+// internal/campaignstate/campaignstate.go
+package campaignstate
+
+import appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+
+// Campaign statuses
+const (
+	Draft     = "draft"
+	Scheduled = "scheduled"
+	Sending   = "sending"
+	Paused    = "paused"
+	Sent      = "sent"
+	Failed    = "failed"
+	Cancelled = "cancelled"
+)
+
+// transitions lists where each status may go next. sent, failed and cancelled are final.
+var transitions = map[string][]string{
+	Draft:     {Scheduled, Sending, Cancelled},
+	Scheduled: {Draft, Sending, Cancelled},
+	Sending:   {Paused, Sent, Failed, Cancelled},
+	Paused:    {Sending, Cancelled},
+}
+
+// Valid reports whether status is a known campaign status
+func Valid(status string) bool {
+	switch status {
+	case Draft, Scheduled, Sending, Paused, Sent, Failed, Cancelled:
+		return true
+	}
+	return false
+}
+
+// Final reports whether a campaign in status can no longer change
+func Final(status string) bool {
+	return Valid(status) && len(transitions[status]) == 0
+}
+
+// Allowed reports whether a campaign may move from one status to another
+func Allowed(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns ErrInvalidTransition unless campaignID may move from one status to another
+func Check(campaignID int, from, to string) error {
+	if !Allowed(from, to) {
+		return appErrors.NewInvalidTransition(campaignID, from, to)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package campaignstate_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/unclebandit/smsleopard-backend/internal/campaignstate"
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+)
+
+func TestTransitions(t *testing.T) {
+	allowed := [][2]string{
+		{"draft", "scheduled"}, {"draft", "sending"}, {"scheduled", "sending"}, {"scheduled", "draft"},
+		{"sending", "paused"}, {"paused", "sending"}, {"sending", "sent"}, {"sending", "failed"},
+		{"draft", "cancelled"}, {"paused", "cancelled"}, {"sending", "cancelled"},
+	}
+	for _, tr := range allowed {
+		if err := campaignstate.Check(1, tr[0], tr[1]); err != nil {
+			t.Errorf("%s -> %s: unexpected error %v", tr[0], tr[1], err)
+		}
+	}
+
+	rejected := [][2]string{
+		{"sending", "sending"}, {"draft", "paused"}, {"paused", "sent"}, {"sent", "sending"},
+		{"cancelled", "sending"}, {"failed", "cancelled"}, {"draft", "bogus"}, {"bogus", "sending"},
+	}
+	for _, tr := range rejected {
+		var invalid *appErrors.ErrInvalidTransition
+		if err := campaignstate.Check(7, tr[0], tr[1]); !errors.As(err, &invalid) || invalid.CampaignID != 7 || invalid.From != tr[0] {
+			t.Errorf("%s -> %s: expected invalid transition, got %v", tr[0], tr[1], err)
+		}
+	}
+}
+
+func TestFinal(t *testing.T) {
+	for status, want := range map[string]bool{"sent": true, "failed": true, "cancelled": true, "sending": false, "draft": false, "bogus": false} {
+		if got := campaignstate.Final(status); got != want {
+			t.Errorf("Final(%q) = %v, want %v", status, got, want)
+		}
+	}
+}
@@ -3,10 +3,12 @@ package controller
 
 import (
     "encoding/json"
+    "io"
     "log"
     "net/http"
     "strconv"
 
+    appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
     "github.com/unclebandit/smsleopard-backend/internal/service"
 
     "github.com/go-chi/chi/v5"
@@ -31,40 +33,104 @@ func (c *CampaignController) PersonalizedPreview(w http.ResponseWriter, r *http.
         return
     }
 
-    rendered, err := c.CampaignService.RenderPreview(campaignID, body.CustomerID, body.OverrideTemplate)
+    preview, err := c.CampaignService.RenderPreview(campaignID, body.CustomerID, body.OverrideTemplate)
     if err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        writeError(w, err)
         return
     }
 
     json.NewEncoder(w).Encode(map[string]interface{}{
-        "rendered_message": rendered,
+        "rendered_message": preview.Message,
+        "language":         preview.Language,
+        "variant":          preview.Variant,
+        "template_parameters": preview.TemplateParameters,
+        "missing_fields":   preview.MissingFields,
+        "encoding":         preview.SMS.Encoding,
+        "characters":       preview.SMS.Characters,
+        "segments":         preview.SMS.Segments,
         "used_template":    body.OverrideTemplate,
         "customer_id":      body.CustomerID,
     })
 }
 
 func (c *CampaignController) CreateCampaign(w http.ResponseWriter, r *http.Request) {
-    var body struct {
-        Name         string  `json:"name"`
-        Channel      string  `json:"channel"`
-        BaseTemplate string  `json:"base_template"`
-        ScheduledAt  *string `json:"scheduled_at"`
+    var body service.CampaignInput
+    if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+        http.Error(w, "invalid body", http.StatusBadRequest)
+        return
+    }
+
+    campaign, err := c.CampaignService.CreateCampaign(body)
+    if err != nil {
+        writeError(w, err)
+        return
     }
+
+    json.NewEncoder(w).Encode(campaign)
+}
+
+func (c *CampaignController) UpdateCampaign(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "invalid campaign id", http.StatusBadRequest)
+        return
+    }
+
+    var body service.CampaignPatch
     if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
         http.Error(w, "invalid body", http.StatusBadRequest)
         return
     }
 
-    campaign, err := c.CampaignService.CreateCampaign(body.Name, body.Channel, body.BaseTemplate, body.ScheduledAt)
+    campaign, err := c.CampaignService.UpdateCampaign(id, body)
     if err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        writeError(w, err)
         return
     }
 
     json.NewEncoder(w).Encode(campaign)
 }
 
+// CloneCampaign copies a campaign into a new draft. The body is optional and holds the same
+// fields as PATCH /campaigns/{id}, overriding what is copied.
+func (c *CampaignController) CloneCampaign(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "invalid campaign id", http.StatusBadRequest)
+        return
+    }
+
+    var body service.CampaignPatch
+    if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+        http.Error(w, "invalid body", http.StatusBadRequest)
+        return
+    }
+
+    campaign, err := c.CampaignService.CloneCampaign(id, body)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(campaign)
+}
+
+func (c *CampaignController) DeleteCampaign(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.Atoi(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "invalid campaign id", http.StatusBadRequest)
+        return
+    }
+
+    if err := c.CampaignService.DeleteCampaign(id); err != nil {
+        writeError(w, err)
+        return
+    }
+
+    w.WriteHeader(http.StatusNoContent)
+}
+
 
 func (c *CampaignController) ListCampaigns(w http.ResponseWriter, r *http.Request) {
     // Parse query parameters
@@ -117,17 +183,47 @@ func (c *CampaignController) SendCampaign(w http.ResponseWriter, r *http.Request
     id, _ := strconv.Atoi(idStr)
 
     var body struct {
-        CustomerIDs []int `json:"customer_ids"`
+        CustomerIDs   []int   `json:"customer_ids"`
+        SegmentID     *int    `json:"segment_id"`
+        TagExpression *string `json:"tag_expression"`
+        UseAudience   bool    `json:"use_audience"`
     }
     if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
         http.Error(w, "invalid body", http.StatusBadRequest)
         return
     }
+    if body.UseAudience && (body.SegmentID != nil || body.TagExpression != nil || body.CustomerIDs != nil) {
+        writeError(w, appErrors.NewValidation("use_audience", "cannot be combined with customer_ids, segment_id or tag_expression"))
+        return
+    }
+    if !body.UseAudience && body.SegmentID == nil && body.TagExpression == nil && len(body.CustomerIDs) == 0 {
+        writeError(w, appErrors.NewValidation("customer_ids", "must not be empty; or give segment_id, tag_expression or use_audience"))
+        return
+    }
+    if body.SegmentID != nil && len(body.CustomerIDs) > 0 {
+        writeError(w, appErrors.NewValidation("segment_id", "cannot be combined with customer_ids"))
+        return
+    }
+    if body.TagExpression != nil && (body.SegmentID != nil || len(body.CustomerIDs) > 0) {
+        writeError(w, appErrors.NewValidation("tag_expression", "cannot be combined with customer_ids or segment_id"))
+        return
+    }
 
-    // Send campaign via service
-    result, err := c.CampaignService.SendCampaign(id, body.CustomerIDs)
+    // Send campaign via service, to a saved segment, a tag expression, the campaign's stored
+    // audience or an explicit list
+    var result *service.SendCampaignResult
+    var err error
+    if body.SegmentID != nil {
+        result, err = c.CampaignService.SendCampaignToSegment(id, *body.SegmentID)
+    } else if body.TagExpression != nil {
+        result, err = c.CampaignService.SendCampaignToTags(id, *body.TagExpression)
+    } else if body.UseAudience {
+        result, err = c.CampaignService.SendCampaignToAudience(id)
+    } else {
+        result, err = c.CampaignService.SendCampaign(id, body.CustomerIDs)
+    }
     if err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        writeError(w, err)
         return
     }
 
@@ -179,12 +275,49 @@ func (c *CampaignController) SendCampaign(w http.ResponseWriter, r *http.Request
 
     // Return JSON response
     json.NewEncoder(w).Encode(map[string]interface{}{
-        "campaign_id":     result.CampaignID,
-        "messages_queued": result.MessagesQueued,
-        "status":          result.Status,
+        "campaign_id":        result.CampaignID,
+        "messages_queued":    result.MessagesQueued,
+        "suppressed":         result.Suppressed,
+        "skipped_no_consent": result.NoConsent,
+        "frequency_capped":   result.Capped,
+        "status":             result.Status,
     })
 }
 
 
 
 
+
+// changeStatus handles the pause, resume and cancel endpoints
+func (c *CampaignController) changeStatus(w http.ResponseWriter, r *http.Request, change func(int) (*service.StatusChange, error)) {
+    id, err := strconv.Atoi(chi.URLParam(r, "id"))
+    if err != nil {
+        http.Error(w, "invalid campaign id", http.StatusBadRequest)
+        return
+    }
+
+    result, err := change(id)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "campaign_id":       result.CampaignID,
+        "previous_status":   result.From,
+        "status":            result.To,
+        "messages_affected": result.Messages,
+    })
+}
+
+func (c *CampaignController) PauseCampaign(w http.ResponseWriter, r *http.Request) {
+    c.changeStatus(w, r, c.CampaignService.PauseCampaign)
+}
+
+func (c *CampaignController) ResumeCampaign(w http.ResponseWriter, r *http.Request) {
+    c.changeStatus(w, r, c.CampaignService.ResumeCampaign)
+}
+
+func (c *CampaignController) CancelCampaign(w http.ResponseWriter, r *http.Request) {
+    c.changeStatus(w, r, c.CampaignService.CancelCampaign)
+}
@@ -12,6 +12,8 @@ import (
 
 	"github.com/unclebandit/smsleopard-backend/internal/controller"
 	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/repository"
+	"github.com/unclebandit/smsleopard-backend/internal/tagexpr"
 	"github.com/unclebandit/smsleopard-backend/internal/service"
 )
 
@@ -36,6 +38,28 @@ func (m *MockCustomerRepo) ListAll() ([]model.Customer, error) {
 	return []model.Customer{}, nil
 }
 
+func (m *MockCustomerRepo) UpdateAttributes(id int, attributes map[string]string) error {
+	return nil
+}
+
+func (m *MockCustomerRepo) List(offset, limit int, location, product string) ([]model.Customer, int, error) {
+	return []model.Customer{}, 0, nil
+}
+
+func (m *MockCustomerRepo) Create(c *model.Customer) error { return nil }
+func (m *MockCustomerRepo) Update(c *model.Customer) error { return nil }
+func (m *MockCustomerRepo) Delete(id int) error            { return nil }
+
+func (m *MockCustomerRepo) UpsertByPhone(c *model.Customer) (bool, error) { return true, nil }
+func (m *MockCustomerRepo) Messages(customerID int) ([]model.OutboundMessage, error) {
+	return []model.OutboundMessage{}, nil
+}
+func (m *MockCustomerRepo) Erase(id int) error { return nil }
+func (m *MockCustomerRepo) AddTags(customerID int, tags []string) error { return nil }
+func (m *MockCustomerRepo) RemoveTag(customerID int, tag string) (bool, error) { return false, nil }
+func (m *MockCustomerRepo) Tags(customerID int) ([]string, error) { return []string{}, nil }
+func (m *MockCustomerRepo) CustomerIDsByTags(expr tagexpr.Expr) ([]int, error) { return []int{}, nil }
+
 type MockCampaignRepo struct{}
 
 func (m *MockCampaignRepo) GetByID(id int) (*model.Campaign, error) {
@@ -46,10 +70,12 @@ func (m *MockCampaignRepo) GetByID(id int) (*model.Campaign, error) {
 
 func (m *MockCampaignRepo) Create(c *model.Campaign) error                    { return nil }
 func (m *MockCampaignRepo) Update(c *model.Campaign) error                    { return nil }
+func (m *MockCampaignRepo) Delete(id int) (bool, error)                    { return true, nil }
 func (m *MockCampaignRepo) ListCampaigns(offset, limit int, channel, status string) ([]*model.Campaign, int, error) {
 	return []*model.Campaign{}, 0, nil
 }
-func (m *MockCampaignRepo) UpdateStatus(id int, status string) error { return nil }
+func (m *MockCampaignRepo) TransitionStatus(id int, from, to string) (bool, error) { return true, nil }
+func (m *MockCampaignRepo) CompleteCampaign(id int, status string, completedAt time.Time) (bool, error) { return true, nil }
 
 // --- Test Function ---
 
@@ -143,8 +169,16 @@ func (m *MockCampaignRepoForPagination) Update(c *model.Campaign) error {
 	return nil
 }
 
-func (m *MockCampaignRepoForPagination) UpdateStatus(id int, status string) error {
-	return nil
+func (m *MockCampaignRepoForPagination) Delete(id int) (bool, error) {
+	return true, nil
+}
+
+func (m *MockCampaignRepoForPagination) TransitionStatus(id int, from, to string) (bool, error) {
+	return true, nil
+}
+
+func (m *MockCampaignRepoForPagination) CompleteCampaign(id int, status string, completedAt time.Time) (bool, error) {
+	return true, nil
 }
 
 func TestListCampaignsPagination(t *testing.T) {
@@ -308,9 +342,11 @@ func (m *MockCampaignRepo) GetOutboundMessageByID(id int) (*model.OutboundMessag
     }, nil
 }
 
+func (m *MockCampaignRepo) ClaimOutboundMessage(id int) (bool, error) { return true, nil }
+
 
 
-func (m *MockCampaignRepo) UpdateOutboundMessageContent(id int, content string) error {
+func (m *MockCampaignRepo) UpdateOutboundMessageContent(msg *model.OutboundMessage) error {
     return nil
 }
 
@@ -321,7 +357,65 @@ func (m *MockCampaignRepoForPagination) GetOutboundMessageByID(id int) (*model.O
     }, nil
 }
 
-func (m *MockCampaignRepoForPagination) UpdateOutboundMessageContent(id int, content string) error {
+func (m *MockCampaignRepoForPagination) ClaimOutboundMessage(id int) (bool, error) { return true, nil }
+
+func (m *MockCampaignRepoForPagination) UpdateOutboundMessageContent(msg *model.OutboundMessage) error {
     // no-op stub
     return nil
 }
+
+func (m *MockCampaignRepo) RecentSends(customerIDs []int, channel string, since time.Time, excludeCampaignID int, includePending bool) (map[int]repository.RecentSends, error) {
+    return map[int]repository.RecentSends{}, nil
+}
+
+func (m *MockCampaignRepo) HoldOutboundMessage(id int, status string, notBefore time.Time, reason string) error {
+    return nil
+}
+
+func (m *MockCampaignRepo) ReleaseDueMessages(now time.Time) ([]int, error) {
+    return []int{}, nil
+}
+
+func (m *MockCampaignRepo) UpdateCampaignMessageStatuses(campaignID int, from []string, to, reason string) ([]int, error) {
+    return []int{}, nil
+}
+
+func (m *MockCampaignRepoForPagination) RecentSends(customerIDs []int, channel string, since time.Time, excludeCampaignID int, includePending bool) (map[int]repository.RecentSends, error) {
+    return map[int]repository.RecentSends{}, nil
+}
+
+func (m *MockCampaignRepoForPagination) HoldOutboundMessage(id int, status string, notBefore time.Time, reason string) error {
+    return nil
+}
+
+func (m *MockCampaignRepoForPagination) ReleaseDueMessages(now time.Time) ([]int, error) {
+    return []int{}, nil
+}
+
+func (m *MockCampaignRepoForPagination) UpdateCampaignMessageStatuses(campaignID int, from []string, to, reason string) ([]int, error) {
+    return []int{}, nil
+}
+
+func TestSendCampaignRequiresATarget(t *testing.T) {
+	repo := &MockCampaignRepo{}
+	ctrl := &controller.CampaignController{CampaignService: &service.CampaignService{CampaignRepo: repo, CustomerRepo: &MockCustomerRepo{}}}
+
+	cases := map[string]string{
+		`{}`:                   "customer_ids",
+		`{"customer_ids": []}`: "customer_ids",
+		`{"use_audience": true, "customer_ids": [1]}`: "use_audience",
+	}
+	for body, field := range cases {
+		req := httptest.NewRequest("POST", "/campaigns/1/send", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		ctrl.SendCampaign(w, req)
+
+		var res struct {
+			Field string `json:"field"`
+		}
+		json.NewDecoder(w.Body).Decode(&res)
+		if w.Code != http.StatusUnprocessableEntity || res.Field != field {
+			t.Errorf("%s: expected 422 on %s, got %d on %q", body, field, w.Code, res.Field)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// internal/controller/consent_controller.go
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+type ConsentController struct {
+	Service *service.ConsentService
+}
+
+// Grant records consent for the customer in the URL
+func (c *ConsentController) Grant(w http.ResponseWriter, r *http.Request) {
+	customerID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid customer id", http.StatusBadRequest)
+		return
+	}
+
+	var body model.Consent
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+	body.ID = 0
+	body.CustomerID = customerID
+
+	if err := c.Service.Grant(&body); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(body)
+}
+
+func (c *ConsentController) List(w http.ResponseWriter, r *http.Request) {
+	customerID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid customer id", http.StatusBadRequest)
+		return
+	}
+
+	consents, err := c.Service.History(customerID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": consents})
+}
+
+func (c *ConsentController) Revoke(w http.ResponseWriter, r *http.Request) {
+	customerID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid customer id", http.StatusBadRequest)
+		return
+	}
+
+	consent, err := c.Service.Revoke(customerID, chi.URLParam(r, "channel"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(consent)
+}
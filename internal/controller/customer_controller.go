@@ -0,0 +1,186 @@
+// internal/controller/customer_controller.go
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+type CustomerController struct {
+	Service *service.CustomerService
+}
+
+func (c *CustomerController) Create(w http.ResponseWriter, r *http.Request) {
+	var body model.Customer
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+	body.ID = 0
+
+	if err := c.Service.Create(&body); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(body)
+}
+
+func (c *CustomerController) List(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	location := r.URL.Query().Get("location")
+	product := r.URL.Query().Get("preferred_product")
+
+	customers, pagination, err := c.Service.List(page, pageSize, location, product)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data":       customers,
+		"pagination": pagination,
+	})
+}
+
+func (c *CustomerController) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid customer id", http.StatusBadRequest)
+		return
+	}
+
+	customer, err := c.Service.Get(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(customer)
+}
+
+func (c *CustomerController) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid customer id", http.StatusBadRequest)
+		return
+	}
+
+	var patch service.CustomerPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+
+	customer, err := c.Service.Update(id, patch)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(customer)
+}
+
+func (c *CustomerController) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid customer id", http.StatusBadRequest)
+		return
+	}
+
+	// mode=erase anonymizes instead, for customers who were already messaged
+	switch r.URL.Query().Get("mode") {
+	case "":
+		err = c.Service.Delete(id)
+	case "erase":
+		err = c.Service.Erase(id)
+	default:
+		err = appErrors.NewValidation("mode", "must be erase or omitted")
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *CustomerController) Export(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid customer id", http.StatusBadRequest)
+		return
+	}
+
+	export, err := c.Service.Export(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="customer-%d.json"`, id))
+	json.NewEncoder(w).Encode(export)
+}
+
+func (c *CustomerController) AddTags(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid customer id", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+
+	tags, err := c.Service.AddTags(id, body.Tags)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"tags": tags})
+}
+
+func (c *CustomerController) Tags(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid customer id", http.StatusBadRequest)
+		return
+	}
+
+	tags, err := c.Service.Tags(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"tags": tags})
+}
+
+func (c *CustomerController) RemoveTag(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid customer id", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.Service.RemoveTag(id, chi.URLParam(r, "tag")); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,139 @@
+// internal/controller/customer_import_controller.go
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+type CustomerImportController struct {
+	Service *service.CustomerImportService
+}
+
+// Import accepts a CSV upload for the customer table. The file is sent either as the raw
+// request body (Content-Type text/csv) or as the "file" part of a multipart form. It is
+// spooled to a temporary file and imported in the background, so the response is the
+// running job, to be polled at its Location.
+func (c *CustomerImportController) Import(w http.ResponseWriter, r *http.Request) {
+	src, filename, err := csvUpload(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	spool, err := spoolUpload(src)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	job, err := c.Service.Start(spool, filename)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/customers/imports/%d", job.ID))
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// spooledFile is an upload copied to disk; closing it removes the file
+type spooledFile struct {
+	*os.File
+}
+
+func (f spooledFile) Close() error {
+	f.File.Close()
+	return os.Remove(f.Name())
+}
+
+// spoolUpload copies the upload to a temporary file that outlives the request
+func spoolUpload(src io.Reader) (io.ReadCloser, error) {
+	file, err := os.CreateTemp("", "customer-import-*.csv")
+	if err != nil {
+		return nil, err
+	}
+	spool := spooledFile{file}
+	if _, err := io.Copy(file, src); err != nil {
+		spool.Close()
+		return nil, appErrors.NewValidation("file", fmt.Sprintf("upload failed: %v", err))
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		spool.Close()
+		return nil, err
+	}
+	return spool, nil
+}
+
+// csvUpload returns a reader over the uploaded CSV without buffering it
+func csvUpload(r *http.Request) (io.Reader, string, error) {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		return r.Body, r.URL.Query().Get("filename"), nil
+	}
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, "", appErrors.NewValidation("file", err.Error())
+	}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil, "", appErrors.NewValidation("file", "is required")
+		}
+		if err != nil {
+			return nil, "", appErrors.NewValidation("file", err.Error())
+		}
+		if part.FormName() == "file" {
+			return part, part.FileName(), nil
+		}
+	}
+}
+
+func (c *CustomerImportController) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid import id", http.StatusBadRequest)
+		return
+	}
+
+	job, err := c.Service.Get(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(job)
+}
+
+// Rows returns the per-row report of an import, optionally filtered by ?outcome=
+func (c *CustomerImportController) Rows(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid import id", http.StatusBadRequest)
+		return
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+
+	rows, pagination, err := c.Service.Rows(id, r.URL.Query().Get("outcome"), page, pageSize)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data":       rows,
+		"pagination": pagination,
+	})
+}
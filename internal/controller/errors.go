@@ -0,0 +1,66 @@
+// internal/controller/errors.go
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+)
+
+// writeError maps service errors onto HTTP status codes
+func writeError(w http.ResponseWriter, err error) {
+	var invalidTemplate *appErrors.ErrInvalidTemplate
+	var campaignNotFound *appErrors.ErrCampaignNotFound
+	var customerNotFound *appErrors.ErrCustomerNotFound
+	var whatsappTemplateNotFound *appErrors.ErrWhatsAppTemplateNotFound
+	var validation *appErrors.ErrValidation
+	var shortLinkNotFound *appErrors.ErrShortLinkNotFound
+	var segmentNotFound *appErrors.ErrSegmentNotFound
+	var conflict *appErrors.ErrConflict
+	var invalidTransition *appErrors.ErrInvalidTransition
+	var importJobNotFound *appErrors.ErrImportJobNotFound
+	var suppressionNotFound *appErrors.ErrSuppressionNotFound
+	var consentNotFound *appErrors.ErrConsentNotFound
+	var tagNotFound *appErrors.ErrTagNotFound
+
+	switch {
+	case errors.As(err, &invalidTemplate):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":    "invalid template",
+			"field":    invalidTemplate.Field,
+			"problems": invalidTemplate.Problems,
+		})
+	case errors.As(err, &validation):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": validation.Message,
+			"field": validation.Field,
+		})
+	case errors.As(err, &conflict):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": conflict.Message,
+			"field": conflict.Field,
+		})
+	case errors.As(err, &invalidTransition):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":  invalidTransition.Error(),
+			"status": invalidTransition.From,
+		})
+	case errors.As(err, &campaignNotFound), errors.As(err, &customerNotFound), errors.As(err, &whatsappTemplateNotFound),
+		errors.As(err, &shortLinkNotFound), errors.As(err, &segmentNotFound),
+		errors.As(err, &importJobNotFound), errors.As(err, &suppressionNotFound),
+		errors.As(err, &consentNotFound), errors.As(err, &tagNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
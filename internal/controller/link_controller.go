@@ -0,0 +1,31 @@
+// internal/controller/link_controller.go
+package controller
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+type LinkController struct {
+	Service *service.LinkService
+}
+
+// Redirect records a click on a short link and sends the customer on to its target
+func (c *LinkController) Redirect(w http.ResponseWriter, r *http.Request) {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	target, err := c.Service.Follow(chi.URLParam(r, "code"), r.UserAgent(), ip)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	http.Redirect(w, r, target, http.StatusFound)
+}
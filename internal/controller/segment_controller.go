@@ -0,0 +1,79 @@
+// internal/controller/segment_controller.go
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+type SegmentController struct {
+	Service *service.SegmentService
+}
+
+func (c *SegmentController) Create(w http.ResponseWriter, r *http.Request) {
+	var body model.Segment
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+
+	segment, err := c.Service.Create(&body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(segment)
+}
+
+func (c *SegmentController) List(w http.ResponseWriter, r *http.Request) {
+	segments, err := c.Service.List()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": segments})
+}
+
+func (c *SegmentController) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid segment id", http.StatusBadRequest)
+		return
+	}
+
+	segment, err := c.Service.Get(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(segment)
+}
+
+// Preview reports how many customers a set of rules matches without saving it
+func (c *SegmentController) Preview(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Rules []model.SegmentRule `json:"rules"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+
+	count, err := c.Service.Preview(body.Rules)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"customer_count": count})
+}
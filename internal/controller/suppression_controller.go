@@ -0,0 +1,67 @@
+// internal/controller/suppression_controller.go
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+type SuppressionController struct {
+	Service *service.SuppressionService
+}
+
+func (c *SuppressionController) Create(w http.ResponseWriter, r *http.Request) {
+	var body model.Suppression
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+	body.ID = 0
+
+	if err := c.Service.Add(&body); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(body)
+}
+
+func (c *SuppressionController) List(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	customerID, _ := strconv.Atoi(r.URL.Query().Get("customer_id"))
+	channel := r.URL.Query().Get("channel")
+
+	suppressions, pagination, err := c.Service.List(page, pageSize, customerID, channel)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data":       suppressions,
+		"pagination": pagination,
+	})
+}
+
+func (c *SuppressionController) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid suppression id", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.Service.Remove(id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,33 @@
+// internal/controller/template_controller.go
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/unclebandit/smsleopard-backend/internal/template"
+)
+
+type TemplateController struct{}
+
+// Validate runs the same checks applied at campaign creation and reports every problem found
+func (c *TemplateController) Validate(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Template  string            `json:"template"`
+		Variables map[string]string `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+
+	problems := template.Validate(body.Template, body.Variables)
+	if problems == nil {
+		problems = template.Errors{}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"valid":    len(problems) == 0,
+		"problems": problems,
+	})
+}
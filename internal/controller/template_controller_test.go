@@ -0,0 +1,97 @@
+package controller_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/unclebandit/smsleopard-backend/internal/controller"
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+	"github.com/unclebandit/smsleopard-backend/internal/template"
+)
+
+type problemsResponse struct {
+	Valid    bool `json:"valid"`
+	Problems []struct {
+		Offset  int    `json:"offset"`
+		Message string `json:"message"`
+	} `json:"problems"`
+}
+
+func TestValidateTemplateEndpoint(t *testing.T) {
+	ctrl := &controller.TemplateController{}
+
+	b, _ := json.Marshal(map[string]string{"template": "Hi {firstname}, see {location"})
+	req := httptest.NewRequest("POST", "/templates/validate", bytes.NewReader(b))
+	w := httptest.NewRecorder()
+
+	ctrl.Validate(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	var res problemsResponse
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if res.Valid {
+		t.Errorf("expected template to be invalid")
+	}
+	if len(res.Problems) != 2 || res.Problems[0].Offset != 3 || res.Problems[1].Offset != 20 {
+		t.Errorf("unexpected problems: %+v", res.Problems)
+	}
+}
+
+func TestCreateCampaignRejectsInvalidTemplate(t *testing.T) {
+	svc := &service.CampaignService{CampaignRepo: &MockCampaignRepo{}}
+	ctrl := &controller.CampaignController{CampaignService: svc}
+
+	b, _ := json.Marshal(map[string]string{
+		"name":          "Typo",
+		"channel":       "sms",
+		"base_template": "Hi {firstname}!",
+	})
+	req := httptest.NewRequest("POST", "/campaigns", bytes.NewReader(b))
+	w := httptest.NewRecorder()
+
+	ctrl.CreateCampaign(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", w.Code)
+	}
+
+	var res problemsResponse
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(res.Problems) != 1 || res.Problems[0].Offset != 3 {
+		t.Errorf("unexpected problems: %+v", res.Problems)
+	}
+}
+
+func TestPreviewRejectsInvalidOverride(t *testing.T) {
+	ctrl := &controller.CampaignController{CampaignService: &service.CampaignService{
+		CampaignRepo: &MockCampaignRepo{},
+		CustomerRepo: &MockCustomerRepo{},
+	}}
+
+	b, _ := json.Marshal(map[string]interface{}{"customer_id": 1, "override_template": "Hi {if location}{location}"})
+	req := httptest.NewRequest("POST", "/campaigns/1/personalized-preview", bytes.NewReader(b))
+	w := httptest.NewRecorder()
+	ctrl.PersonalizedPreview(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", w.Code, w.Body.String())
+	}
+	var res struct {
+		Field    string            `json:"field"`
+		Problems []*template.Error `json:"problems"`
+	}
+	json.NewDecoder(w.Body).Decode(&res)
+	if res.Field != "override_template" || len(res.Problems) != 1 || res.Problems[0].Offset != 3 {
+		t.Errorf("expected unclosed {if} at offset 3, got %+v", res)
+	}
+}
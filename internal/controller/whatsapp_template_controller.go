@@ -0,0 +1,59 @@
+// internal/controller/whatsapp_template_controller.go
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+type WhatsAppTemplateController struct {
+	Service *service.WhatsAppTemplateService
+}
+
+func (c *WhatsAppTemplateController) Create(w http.ResponseWriter, r *http.Request) {
+	var body model.WhatsAppTemplate
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.Service.Register(&body); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(body)
+}
+
+func (c *WhatsAppTemplateController) List(w http.ResponseWriter, r *http.Request) {
+	templates, err := c.Service.List()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": templates})
+}
+
+func (c *WhatsAppTemplateController) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid template id", http.StatusBadRequest)
+		return
+	}
+
+	t, err := c.Service.Get(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(t)
+}
@@ -1,7 +1,11 @@
 // internal/errors/errors.go
 package appErrors
 
-import "fmt"
+import (
+    "fmt"
+
+    "github.com/unclebandit/smsleopard-backend/internal/template"
+)
 
 // ErrCampaignNotFound is a sentinel error
 type ErrCampaignNotFound struct {
@@ -16,3 +20,178 @@ func (e *ErrCampaignNotFound) Error() string {
 func NewCampaignNotFound(id int) error {
     return &ErrCampaignNotFound{CampaignID: id}
 }
+
+// ErrCustomerNotFound is returned when a customer does not exist
+type ErrCustomerNotFound struct {
+    CustomerID int
+}
+
+func (e *ErrCustomerNotFound) Error() string {
+    return fmt.Sprintf("customer with ID %d not found", e.CustomerID)
+}
+
+// Helper constructor
+func NewCustomerNotFound(id int) error {
+    return &ErrCustomerNotFound{CustomerID: id}
+}
+
+// ErrInvalidTemplate is returned when a campaign template fails validation
+type ErrInvalidTemplate struct {
+    Field    string
+    Problems template.Errors
+}
+
+func (e *ErrInvalidTemplate) Error() string {
+    return fmt.Sprintf("%s has %d problem(s): %s", e.Field, len(e.Problems), e.Problems.Error())
+}
+
+// Helper constructor
+func NewInvalidTemplate(field string, problems template.Errors) error {
+    return &ErrInvalidTemplate{Field: field, Problems: problems}
+}
+
+// ErrWhatsAppTemplateNotFound is returned when a campaign references an unregistered template
+type ErrWhatsAppTemplateNotFound struct {
+    TemplateID int
+}
+
+func (e *ErrWhatsAppTemplateNotFound) Error() string {
+    return fmt.Sprintf("whatsapp template with ID %d not found", e.TemplateID)
+}
+
+// Helper constructor
+func NewWhatsAppTemplateNotFound(id int) error {
+    return &ErrWhatsAppTemplateNotFound{TemplateID: id}
+}
+
+// ErrValidation is returned when a request field has an invalid value
+type ErrValidation struct {
+    Field   string
+    Message string
+}
+
+func (e *ErrValidation) Error() string {
+    return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// Helper constructor
+func NewValidation(field, message string) error {
+    return &ErrValidation{Field: field, Message: message}
+}
+
+// ErrShortLinkNotFound is returned when a short link code does not exist
+type ErrShortLinkNotFound struct {
+    Code string
+}
+
+func (e *ErrShortLinkNotFound) Error() string {
+    return fmt.Sprintf("short link %q not found", e.Code)
+}
+
+// Helper constructor
+func NewShortLinkNotFound(code string) error {
+    return &ErrShortLinkNotFound{Code: code}
+}
+
+// ErrSegmentNotFound is returned when an audience segment does not exist
+type ErrSegmentNotFound struct {
+    SegmentID int
+}
+
+func (e *ErrSegmentNotFound) Error() string {
+    return fmt.Sprintf("segment with ID %d not found", e.SegmentID)
+}
+
+// Helper constructor
+func NewSegmentNotFound(id int) error {
+    return &ErrSegmentNotFound{SegmentID: id}
+}
+
+// ErrConflict is returned when a change clashes with existing data, e.g. a duplicate phone
+type ErrConflict struct {
+    Field   string
+    Message string
+}
+
+func (e *ErrConflict) Error() string {
+    return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// Helper constructor
+func NewConflict(field, message string) error {
+    return &ErrConflict{Field: field, Message: message}
+}
+
+// ErrImportJobNotFound is returned when a customer import job does not exist
+type ErrImportJobNotFound struct {
+    JobID int
+}
+
+func (e *ErrImportJobNotFound) Error() string {
+    return fmt.Sprintf("import job with ID %d not found", e.JobID)
+}
+
+// Helper constructor
+func NewImportJobNotFound(id int) error {
+    return &ErrImportJobNotFound{JobID: id}
+}
+
+// ErrSuppressionNotFound is returned when a suppression list entry does not exist
+type ErrSuppressionNotFound struct {
+    SuppressionID int
+}
+
+func (e *ErrSuppressionNotFound) Error() string {
+    return fmt.Sprintf("suppression with ID %d not found", e.SuppressionID)
+}
+
+// Helper constructor
+func NewSuppressionNotFound(id int) error {
+    return &ErrSuppressionNotFound{SuppressionID: id}
+}
+
+// ErrConsentNotFound is returned when a customer holds no active consent for a channel
+type ErrConsentNotFound struct {
+    CustomerID int
+    Channel    string
+}
+
+func (e *ErrConsentNotFound) Error() string {
+    return fmt.Sprintf("customer %d has no active %s consent", e.CustomerID, e.Channel)
+}
+
+// Helper constructor
+func NewConsentNotFound(customerID int, channel string) error {
+    return &ErrConsentNotFound{CustomerID: customerID, Channel: channel}
+}
+
+// ErrTagNotFound is returned when a customer does not carry a tag
+type ErrTagNotFound struct {
+    CustomerID int
+    Tag        string
+}
+
+func (e *ErrTagNotFound) Error() string {
+    return fmt.Sprintf("customer %d is not tagged %q", e.CustomerID, e.Tag)
+}
+
+// Helper constructor
+func NewTagNotFound(customerID int, tag string) error {
+    return &ErrTagNotFound{CustomerID: customerID, Tag: tag}
+}
+
+// ErrInvalidTransition is returned when a campaign cannot move from its current status to another
+type ErrInvalidTransition struct {
+    CampaignID int
+    From       string
+    To         string
+}
+
+func (e *ErrInvalidTransition) Error() string {
+    return fmt.Sprintf("campaign %d cannot move from %s to %s", e.CampaignID, e.From, e.To)
+}
+
+// Helper constructor
+func NewInvalidTransition(campaignID int, from, to string) error {
+    return &ErrInvalidTransition{CampaignID: campaignID, From: from, To: to}
+}
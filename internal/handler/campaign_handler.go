@@ -3,12 +3,14 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 	"log"
 
 	"github.com/go-chi/chi/v5"
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
 	"github.com/unclebandit/smsleopard-backend/internal/model"
 	"github.com/unclebandit/smsleopard-backend/internal/repository"
 	"github.com/unclebandit/smsleopard-backend/internal/service"
@@ -128,6 +130,11 @@ func (h *CampaignHandler) GetCampaignHandlerWithStats(w http.ResponseWriter, r *
 	log.Println("📥 Handler called for campaign ID:", id)
 
 	details, err := h.Service.GetCampaignDetailsWithStats(id)
+	var notFound *appErrors.ErrCampaignNotFound
+	if errors.As(err, &notFound) {
+		http.Error(w, err.Error(), http.StatusNotFound) // includes deleted campaigns
+		return
+	}
 	if err != nil {
 		log.Println("❌ Error fetching campaign:", err)
 		http.Error(w, "failed to fetch campaign: "+err.Error(), http.StatusInternalServerError)
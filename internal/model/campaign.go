@@ -4,13 +4,46 @@ package model
 import "time"
 
 type Campaign struct {
-    ID           int       `db:"id" json:"id"`
-    Name         string    `db:"name" json:"name"`
-    Channel      string    `db:"channel" json:"channel"`
-    Status       string    `db:"status" json:"status"`
-    BaseTemplate string    `db:"base_template" json:"base_template"`
-    ScheduledAt  *time.Time `db:"scheduled_at" json:"scheduled_at,omitempty"`
-    CreatedAt    time.Time `db:"created_at" json:"created_at"`
-    UpdatedAt    *time.Time `db:"updated_at" json:"updated_at,omitempty"`
+    ID                 int               `db:"id" json:"id"`
+    Name               string            `db:"name" json:"name"`
+    Channel            string            `db:"channel" json:"channel"`
+    Status             string            `db:"status" json:"status"`
+    BaseTemplate       string            `db:"base_template" json:"base_template"`
+    TemplateVariants   map[string]string `db:"template_variants" json:"template_variants,omitempty"` // language -> template
+    FallbackLanguage   string            `db:"fallback_language" json:"fallback_language,omitempty"`
+    Variants           []CampaignVariant `db:"variants" json:"variants,omitempty"` // A/B/n message variants
+    Variables          map[string]string `db:"variables" json:"variables"` // rendered as {campaign.<key>}
+    WhatsAppTemplateID *int              `db:"whatsapp_template_id" json:"whatsapp_template_id,omitempty"`
+    WhatsAppParameters []string          `db:"whatsapp_parameters" json:"whatsapp_parameters,omitempty"` // one template per positional parameter
+    FrequencyCapPolicy string            `db:"frequency_cap_policy" json:"frequency_cap_policy"` // defer or skip customers over the cap
+    QuietHours         *QuietHours       `db:"quiet_hours" json:"quiet_hours,omitempty"` // overrides the global default
+    Audience           *CampaignAudience `db:"audience" json:"audience,omitempty"` // who the campaign is sent to
+    SourceCampaignID   *int              `db:"source_campaign_id" json:"source_campaign_id,omitempty"` // set on clones
+    ScheduledAt        *time.Time        `db:"scheduled_at" json:"scheduled_at,omitempty"`
+    CompletedAt        *time.Time        `db:"completed_at" json:"completed_at,omitempty"` // when sending finished
+    CreatedAt          time.Time         `db:"created_at" json:"created_at"`
+    UpdatedAt          *time.Time        `db:"updated_at" json:"updated_at,omitempty"`
 
 }
+
+// CampaignVariant is one arm of an A/B/n test. Weight is the percentage of customers who receive it.
+type CampaignVariant struct {
+    Label    string `json:"label"`
+    Template string `json:"template"`
+    Weight   int    `json:"weight"`
+}
+
+// CampaignAudience is who a campaign targets: an explicit list of customers, a saved segment
+// or a tag expression. Segments and tag expressions are resolved when the campaign is sent.
+type CampaignAudience struct {
+    CustomerIDs   []int  `json:"customer_ids,omitempty"`
+    SegmentID     *int   `json:"segment_id,omitempty"`
+    TagExpression string `json:"tag_expression,omitempty"`
+}
+
+// QuietHours is a daily window, in the customer's local time, in which nothing is delivered.
+// Start and End are "HH:MM"; the window may cross midnight, and Start equal to End means none.
+type QuietHours struct {
+    Start string `json:"start"`
+    End   string `json:"end"`
+}
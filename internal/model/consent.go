@@ -0,0 +1,15 @@
+// internal/model/consent.go
+package model
+
+import "time"
+
+// Consent records a customer agreeing to marketing messages on a channel. A consent is
+// valid until RevokedAt is set.
+type Consent struct {
+    ID         int        `db:"id" json:"id"`
+    CustomerID int        `db:"customer_id" json:"customer_id"`
+    Channel    string     `db:"channel" json:"channel"` // sms or whatsapp
+    Source     string     `db:"source" json:"source"`
+    GrantedAt  time.Time  `db:"granted_at" json:"granted_at"`
+    RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+}
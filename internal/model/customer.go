@@ -1,11 +1,17 @@
 // internal/model/customer.go
 package model
 
+import "time"
+
 type Customer struct {
-    ID               int    `db:"id" json:"id"`
-    Phone            string `db:"phone" json:"phone"`
-    FirstName        string `db:"first_name" json:"first_name"`
-    LastName         string `db:"last_name" json:"last_name"`
-    Location         string `db:"location" json:"location"`
-    PreferredProduct string `db:"preferred_product" json:"preferred_product"`
+    ID               int               `db:"id" json:"id"`
+    Phone            string            `db:"phone" json:"phone"`
+    FirstName        string            `db:"first_name" json:"first_name"`
+    LastName         string            `db:"last_name" json:"last_name"`
+    Location         string            `db:"location" json:"location"`
+    PreferredProduct string            `db:"preferred_product" json:"preferred_product"`
+    Language         string            `db:"language" json:"language,omitempty"` // e.g. en, sw
+    Timezone         string            `db:"timezone" json:"timezone,omitempty"` // IANA name, e.g. Africa/Nairobi
+    Attributes       map[string]string `db:"attributes" json:"attributes"` // rendered as {attr.<key>}
+    ErasedAt         *time.Time        `db:"erased_at" json:"erased_at,omitempty"` // set once personal data was erased
 }
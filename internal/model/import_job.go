@@ -0,0 +1,27 @@
+// internal/model/import_job.go
+package model
+
+import "time"
+
+// ImportJob tracks one bulk CSV customer import
+type ImportJob struct {
+    ID          int        `db:"id" json:"id"`
+    Filename    string     `db:"filename" json:"filename"`
+    Status      string     `db:"status" json:"status"` // running, completed, failed
+    TotalRows   int        `db:"total_rows" json:"total_rows"`
+    Accepted    int        `db:"accepted" json:"accepted"` // new customers
+    Updated     int        `db:"updated" json:"updated"`   // existing customers matched by phone
+    Rejected    int        `db:"rejected" json:"rejected"`
+    Error       string     `db:"error" json:"error,omitempty"` // why a failed job stopped
+    CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+    CompletedAt *time.Time `db:"completed_at" json:"completed_at,omitempty"`
+}
+
+// ImportRow is the outcome of one CSV row; RowNumber counts the header as row 1
+type ImportRow struct {
+    RowNumber  int    `db:"row_number" json:"row_number"`
+    Phone      string `db:"phone" json:"phone"`
+    Outcome    string `db:"outcome" json:"outcome"` // accepted, updated, rejected
+    Reason     string `db:"reason" json:"reason,omitempty"`
+    CustomerID *int   `db:"customer_id" json:"customer_id,omitempty"`
+}
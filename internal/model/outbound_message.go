@@ -4,13 +4,18 @@ package model
 import "time"
 
 type OutboundMessage struct {
-    ID              int       `db:"id" json:"id"`
-    CampaignID      int       `db:"campaign_id" json:"campaign_id"`
-    CustomerID      int       `db:"customer_id" json:"customer_id"`
-    Status          string    `db:"status" json:"status"` // pending, sent, failed
-    RenderedContent string    `db:"rendered_content" json:"rendered_content"`
-    LastError       string    `db:"last_error,omitempty" json:"last_error,omitempty"`
-    RetryCount      int       `db:"retry_count" json:"retry_count"`
-    CreatedAt       time.Time `db:"created_at" json:"created_at"`
-    UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
+    ID                 int       `db:"id" json:"id"`
+    CampaignID         int       `db:"campaign_id" json:"campaign_id"`
+    CustomerID         int       `db:"customer_id" json:"customer_id"`
+    Status             string    `db:"status" json:"status"` // pending, sent, failed, suppressed, deferred, skipped
+    RenderedContent    string    `db:"rendered_content" json:"rendered_content"`
+    TemplateParameters []string  `db:"template_parameters" json:"template_parameters,omitempty"` // WhatsApp template values
+    Variant            string    `db:"variant" json:"variant,omitempty"` // A/B variant label
+    LastError          string    `db:"last_error,omitempty" json:"last_error,omitempty"`
+    RetryCount         int       `db:"retry_count" json:"retry_count"`
+    Segments           int       `db:"segments" json:"segments"`
+    SentAt             *time.Time `db:"sent_at" json:"sent_at,omitempty"`
+    NotBefore          *time.Time `db:"not_before" json:"not_before,omitempty"` // when a deferred message is retried
+    CreatedAt          time.Time `db:"created_at" json:"created_at"`
+    UpdatedAt          time.Time `db:"updated_at" json:"updated_at"`
 }
@@ -0,0 +1,20 @@
+// internal/model/segment.go
+package model
+
+import "time"
+
+// Segment is a saved audience: every customer matching all of its rules
+type Segment struct {
+    ID        int           `db:"id" json:"id"`
+    Name      string        `db:"name" json:"name"`
+    Rules     []SegmentRule `db:"rules" json:"rules"`
+    CreatedAt time.Time     `db:"created_at" json:"created_at"`
+}
+
+// SegmentRule filters customers on one field, e.g. location in [Nairobi, Mombasa]
+type SegmentRule struct {
+    Field  string   `json:"field"`            // customer field or attr.<key>
+    Op     string   `json:"op"`               // eq, neq, in, not_in, gt, gte, lt, lte
+    Value  string   `json:"value,omitempty"`  // eq, neq and comparisons
+    Values []string `json:"values,omitempty"` // in, not_in
+}
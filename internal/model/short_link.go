@@ -0,0 +1,13 @@
+// internal/model/short_link.go
+package model
+
+import "time"
+
+// ShortLink is a tracked URL created for one outbound message's {link:...} target
+type ShortLink struct {
+    ID                int       `db:"id" json:"id"`
+    Code              string    `db:"code" json:"code"`
+    OutboundMessageID int       `db:"outbound_message_id" json:"outbound_message_id"`
+    TargetURL         string    `db:"target_url" json:"target_url"`
+    CreatedAt         time.Time `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,13 @@
+// internal/model/suppression.go
+package model
+
+import "time"
+
+// Suppression stops a customer from receiving messages on a channel, e.g. after replying STOP
+type Suppression struct {
+    ID         int       `db:"id" json:"id"`
+    CustomerID int       `db:"customer_id" json:"customer_id"`
+    Channel    string    `db:"channel" json:"channel"` // sms, whatsapp or all
+    Reason     string    `db:"reason" json:"reason"`
+    CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
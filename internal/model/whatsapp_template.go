@@ -0,0 +1,17 @@
+// internal/model/whatsapp_template.go
+package model
+
+import "time"
+
+// WhatsAppTemplate is a message template pre-approved by WhatsApp. Business-initiated
+// messages outside the 24h customer service window must use one of these.
+type WhatsAppTemplate struct {
+    ID              int       `db:"id" json:"id"`
+    Name            string    `db:"name" json:"name"`
+    Language        string    `db:"language" json:"language"`
+    Body            string    `db:"body" json:"body"` // approved text with {{1}}..{{n}} parameters
+    ParameterCount  int       `db:"parameter_count" json:"parameter_count"`
+    HeaderMediaType string    `db:"header_media_type" json:"header_media_type,omitempty"` // image, video or document
+    HeaderMediaURL  string    `db:"header_media_url" json:"header_media_url,omitempty"`
+    CreatedAt       time.Time `db:"created_at" json:"created_at"`
+}
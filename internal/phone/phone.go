@@ -0,0 +1,106 @@
+// internal/phone/phone.go
+package phone
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Plan describes how a country's mobile numbers are written
+type Plan struct {
+	Country     string   // ISO 3166-1 alpha-2 code, e.g. "KE"
+	CallingCode string   // e.g. "254"
+	TrunkPrefix string   // dialled before national numbers, e.g. "0"
+	Length      int      // digits in the national number after the trunk prefix
+	Prefixes    []string // leading digits of valid mobile national numbers
+}
+
+// Plans are the countries whose local formats can be converted
+var Plans = map[string]Plan{
+	"KE": {Country: "KE", CallingCode: "254", TrunkPrefix: "0", Length: 9, Prefixes: []string{"7", "1"}},
+	"UG": {Country: "UG", CallingCode: "256", TrunkPrefix: "0", Length: 9, Prefixes: []string{"7"}},
+	"TZ": {Country: "TZ", CallingCode: "255", TrunkPrefix: "0", Length: 9, Prefixes: []string{"6", "7"}},
+	"RW": {Country: "RW", CallingCode: "250", TrunkPrefix: "0", Length: 9, Prefixes: []string{"7"}},
+}
+
+// Default converts local numbers as Kenyan
+var Default = &Normalizer{Country: "KE"}
+
+// ForCountry returns a normalizer for country's local format, or Default when country is
+// empty. Unknown countries are an error, so a typo in configuration fails at startup rather
+// than on every customer write.
+func ForCountry(country string) (*Normalizer, error) {
+	if country == "" {
+		return Default, nil
+	}
+	if _, ok := Plans[country]; !ok {
+		return nil, fmt.Errorf("unknown phone country %q", country)
+	}
+	return &Normalizer{Country: country}, nil
+}
+
+// formatting is stripped before parsing, e.g. "0710 000-001" or "(0710) 000.001"
+var formatting = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+
+// Normalizer converts phone numbers to E.164, e.g. "0710000001" to "+254710000001"
+type Normalizer struct {
+	// Country is the plan applied to numbers written without a country code
+	Country string
+}
+
+// Normalize returns phone in E.164 form. Numbers of a known plan must have that plan's
+// length and a mobile prefix; other international numbers are only checked for E.164 length.
+func (n *Normalizer) Normalize(phone string) (string, error) {
+	digits := formatting.Replace(strings.TrimSpace(phone))
+	if digits == "" {
+		return "", fmt.Errorf("is required")
+	}
+
+	international := false
+	switch {
+	case strings.HasPrefix(digits, "+"):
+		digits, international = digits[1:], true
+	case strings.HasPrefix(digits, "00"):
+		digits, international = digits[2:], true
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", fmt.Errorf("may only contain digits, spaces and -().+")
+		}
+	}
+
+	local, ok := Plans[n.Country]
+	if !international {
+		if !ok {
+			return "", fmt.Errorf("must include a country code, e.g. +254...")
+		}
+		// a country code typed without + or 00, e.g. 254710000001
+		if len(digits) == len(local.CallingCode)+local.Length && strings.HasPrefix(digits, local.CallingCode) {
+			return local.format(digits[len(local.CallingCode):])
+		}
+		return local.format(strings.TrimPrefix(digits, local.TrunkPrefix))
+	}
+
+	for _, plan := range Plans {
+		if strings.HasPrefix(digits, plan.CallingCode) {
+			return plan.format(digits[len(plan.CallingCode):])
+		}
+	}
+	if len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return "", fmt.Errorf("must be a country code followed by a number, 8 to 15 digits in all")
+	}
+	return "+" + digits, nil
+}
+
+// format checks a national number against the plan and prefixes the calling code
+func (p Plan) format(national string) (string, error) {
+	if len(national) != p.Length {
+		return "", fmt.Errorf("must have %d digits after +%s (or %d after the leading %s)", p.Length, p.CallingCode, p.Length, p.TrunkPrefix)
+	}
+	for _, prefix := range p.Prefixes {
+		if strings.HasPrefix(national, prefix) {
+			return "+" + p.CallingCode + national, nil
+		}
+	}
+	return "", fmt.Errorf("is not a %s mobile number", p.Country)
+}
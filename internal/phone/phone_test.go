@@ -0,0 +1,72 @@
+package phone_test
+
+import (
+	"testing"
+
+	"github.com/unclebandit/smsleopard-backend/internal/phone"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		name  string
+		in    string
+		want  string
+		valid bool
+	}{
+		{"local with trunk prefix", "0710000001", "+254710000001", true},
+		{"local formatting", "0710 000-001", "+254710000001", true},
+		{"local without trunk prefix", "710000001", "+254710000001", true},
+		{"new safaricom range", "0110000001", "+254110000001", true},
+		{"already e164", "+254710000001", "+254710000001", true},
+		{"international 00", "00254710000001", "+254710000001", true},
+		{"country code without plus", "254710000001", "+254710000001", true},
+		{"other configured country", "+256 772 000001", "+256772000001", true},
+		{"unconfigured country", "+44 20 7946 0958", "+442079460958", true},
+		{"too short", "071000001", "", false},
+		{"too long", "07100000011", "", false},
+		{"landline prefix", "0201234567", "", false},
+		{"wrong length for country", "+25471000001", "", false},
+		{"letters", "call me", "", false},
+		{"empty", "  ", "", false},
+		{"international too short", "+1234567", "", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := phone.Default.Normalize(c.in)
+			if c.valid && (err != nil || got != c.want) {
+				t.Errorf("Normalize(%q) = %q, %v; want %q", c.in, got, err, c.want)
+			}
+			if !c.valid && err == nil {
+				t.Errorf("Normalize(%q) = %q; want an error", c.in, got)
+			}
+		})
+	}
+}
+
+func TestNormalizeOtherDefaultCountry(t *testing.T) {
+	n := &phone.Normalizer{Country: "UG"}
+	if got, err := n.Normalize("0772000001"); err != nil || got != "+256772000001" {
+		t.Errorf("expected Ugandan local number, got %q (%v)", got, err)
+	}
+
+	unknown := &phone.Normalizer{Country: "XX"}
+	if _, err := unknown.Normalize("0772000001"); err == nil {
+		t.Error("expected local number without a known plan to be rejected")
+	}
+	if got, err := unknown.Normalize("+256772000001"); err != nil || got != "+256772000001" {
+		t.Errorf("expected international number to be accepted, got %q (%v)", got, err)
+	}
+}
+
+func TestForCountry(t *testing.T) {
+	if n, err := phone.ForCountry(""); err != nil || n != phone.Default {
+		t.Errorf("expected the default normalizer, got %+v (%v)", n, err)
+	}
+	if n, err := phone.ForCountry("TZ"); err != nil || n.Country != "TZ" {
+		t.Errorf("expected a Tanzanian normalizer, got %+v (%v)", n, err)
+	}
+	if _, err := phone.ForCountry("KEN"); err == nil {
+		t.Error("expected an unknown country to be rejected")
+	}
+}
@@ -6,8 +6,6 @@ import (
 	"math/rand"
 	"sync"
 	"time"
-
-	"github.com/unclebandit/smsleopard-backend/internal/repository"
 )
 
 // Queue interface
@@ -90,7 +88,8 @@ func (q *InMemoryQueue) Subscribe(topic string, handler func(payload any) error)
 	return nil
 }
 
-func StartCampaignSendSubscriber(q Queue, campaignRepo repository.CampaignRepositoryInterface) {
+// StartCampaignSendSubscriber hands each queued outbound message ID to deliver
+func StartCampaignSendSubscriber(q Queue, deliver func(msgID int) error) {
     go func() {
         err := q.Subscribe("campaign_sends", func(payload any) error {
             // Type assertion: payload should be an int (OutboundMessage ID)
@@ -102,33 +101,10 @@ func StartCampaignSendSubscriber(q Queue, campaignRepo repository.CampaignReposi
 
             log.Println("📩 Processing queued outbound message ID:", msgID)
 
-            // Fetch message details from DB
-            msg, err := campaignRepo.GetOutboundMessageByID(msgID)
-            if err != nil {
-                log.Println("⚠️ Failed to fetch message:", err)
-                return err
-            }
-            if msg == nil {
-                log.Println("⚠️ Message not found for ID:", msgID)
-                return nil // no retry
-            }
-
-            // TODO: Replace MockSender with actual SMS/email sending logic
-            err = MockSender(msg.RenderedContent)
-            if err != nil {
-                log.Println("⚠️ Failed to send message:", err)
-                // Update message status to "failed"
-                _ = campaignRepo.UpdateOutboundMessageStatus(msgID, "failed", err.Error())
+            if err := deliver(msgID); err != nil {
                 return err // triggers retry in queue
             }
 
-            // Update message status to "sent"
-            err = campaignRepo.UpdateOutboundMessageStatus(msgID, "sent", "")
-            if err != nil {
-                log.Println("⚠️ Failed to update message status:", err)
-                return err // retry
-            }
-
             log.Println("✅ Message processed successfully:", msgID)
             return nil
         })
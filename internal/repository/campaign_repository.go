@@ -6,6 +6,7 @@ import (
     "time"
     "log"
 
+    "github.com/lib/pq"
     appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
     "github.com/unclebandit/smsleopard-backend/internal/model"
 )
@@ -14,8 +15,10 @@ type CampaignRepositoryInterface interface {
     // Campaign CRUD
     ListCampaigns(offset, limit int, channel, status string) ([]*model.Campaign, int, error)
     GetByID(id int) (*model.Campaign, error)
-    UpdateStatus(campaignID int, status string) error
+    TransitionStatus(campaignID int, from, to string) (bool, error)
+    CompleteCampaign(campaignID int, status string, completedAt time.Time) (bool, error)
     Update(c *model.Campaign) error
+    Delete(id int) (bool, error)
     Create(c *model.Campaign) error
 
     // Outbound messages
@@ -23,8 +26,33 @@ type CampaignRepositoryInterface interface {
     GetOutboundMessage(campaignID, customerID int) (*model.OutboundMessage, error)
     UpdateOutboundMessageStatus(id int, status, lastError string) error
     GetCampaignStats(campaignID int) (map[string]int, error)
-    UpdateOutboundMessageContent(id int, content string) error
+    UpdateOutboundMessageContent(msg *model.OutboundMessage) error
     GetOutboundMessageByID(id int) (*model.OutboundMessage, error)
+    ClaimOutboundMessage(id int) (bool, error)
+
+    // Frequency capping and quiet hours
+    RecentSends(customerIDs []int, channel string, since time.Time, excludeCampaignID int, includePending bool) (map[int]RecentSends, error)
+    HoldOutboundMessage(id int, status string, notBefore time.Time, reason string) error
+    ReleaseDueMessages(now time.Time) ([]int, error)
+
+    // Pause, resume and cancel
+    UpdateCampaignMessageStatuses(campaignID int, from []string, to, reason string) ([]int, error)
+}
+
+// UnfinishedMessageStatuses are the outbound message statuses still waiting for an outcome.
+// Completion waits for them, cancelling a campaign cancels them and erasing a customer
+// suppresses them.
+var UnfinishedMessageStatuses = []string{"pending", "retrying", "sending", "deferred", "held", "paused"}
+
+// StaleDeliveryAfter is how long a message may stay sending before ReleaseDueMessages
+// assumes its worker died and queues it again
+const StaleDeliveryAfter = 10 * time.Minute
+
+// RecentSends is how many messages a customer was sent within a window, and when the
+// oldest of them went out
+type RecentSends struct {
+    Count  int
+    Oldest time.Time
 }
 
 type CampaignRepository struct {
@@ -33,54 +61,188 @@ type CampaignRepository struct {
 
 // ====================== Campaign CRUD ======================
 
+const campaignColumns = `id, name, channel, status, base_template, template_variants, COALESCE(fallback_language, ''),
+    variants, variables, whatsapp_template_id, whatsapp_parameters, frequency_cap_policy, quiet_hours, audience, source_campaign_id, scheduled_at, completed_at, created_at, updated_at`
+
+type rowScanner interface {
+    Scan(dest ...interface{}) error
+}
+
+// scanCampaign reads a row selected with campaignColumns
+func scanCampaign(row rowScanner) (*model.Campaign, error) {
+    var c model.Campaign
+    var templateVariants, variants, variables, whatsappParameters, quietHours, audience []byte
+    err := row.Scan(&c.ID, &c.Name, &c.Channel, &c.Status, &c.BaseTemplate, &templateVariants, &c.FallbackLanguage,
+        &variants, &variables, &c.WhatsAppTemplateID, &whatsappParameters, &c.FrequencyCapPolicy, &quietHours, &audience, &c.SourceCampaignID,
+        &c.ScheduledAt, &c.CompletedAt, &c.CreatedAt, &c.UpdatedAt)
+    if err != nil {
+        return nil, err
+    }
+    if c.TemplateVariants, err = decodeStringMap(templateVariants); err != nil {
+        return nil, err
+    }
+    if err := decodeJSON(variants, &c.Variants); err != nil {
+        return nil, err
+    }
+    if c.Variables, err = decodeStringMap(variables); err != nil {
+        return nil, err
+    }
+    if err := decodeJSON(whatsappParameters, &c.WhatsAppParameters); err != nil {
+        return nil, err
+    }
+    if len(quietHours) > 0 {
+        c.QuietHours = &model.QuietHours{}
+        if err := decodeJSON(quietHours, c.QuietHours); err != nil {
+            return nil, err
+        }
+    }
+    if len(audience) > 0 {
+        c.Audience = &model.CampaignAudience{}
+        if err := decodeJSON(audience, c.Audience); err != nil {
+            return nil, err
+        }
+    }
+    return &c, nil
+}
+
 func (r *CampaignRepository) Create(c *model.Campaign) error {
     c.CreatedAt = time.Now()
     if c.Status == "" {
         c.Status = "draft"
     }
+    if c.FrequencyCapPolicy == "" {
+        c.FrequencyCapPolicy = "defer"
+    }
+    j, err := encodeCampaignJSON(c)
+    if err != nil {
+        return err
+    }
     query := `
-        INSERT INTO campaigns (name, channel, status, base_template, scheduled_at, created_at)
-        VALUES ($1, $2, $3, $4, $5, $6)
+        INSERT INTO campaigns (name, channel, status, base_template, template_variants, fallback_language,
+            variants, variables, whatsapp_template_id, whatsapp_parameters, frequency_cap_policy, quiet_hours, scheduled_at, created_at,
+            audience, source_campaign_id)
+        VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
         RETURNING id
     `
-    return r.DB.QueryRow(query, c.Name, c.Channel, c.Status, c.BaseTemplate, c.ScheduledAt, c.CreatedAt).Scan(&c.ID)
+    return r.DB.QueryRow(query, c.Name, c.Channel, c.Status, c.BaseTemplate, j.templateVariants, c.FallbackLanguage,
+        j.variants, j.variables, c.WhatsAppTemplateID, j.whatsappParameters, c.FrequencyCapPolicy, j.quietHours, c.ScheduledAt, c.CreatedAt,
+        j.audience, c.SourceCampaignID).Scan(&c.ID)
 }
 
+// campaignJSON holds the encoded JSONB columns of a campaign
+type campaignJSON struct {
+    templateVariants   string
+    variants           string
+    variables          string
+    whatsappParameters string
+    quietHours         interface{} // NULL unless the campaign overrides quiet hours
+    audience           interface{} // NULL until an audience is set
+}
+
+func encodeCampaignJSON(c *model.Campaign) (*campaignJSON, error) {
+    var j campaignJSON
+    var err error
+    if j.templateVariants, err = encodeStringMap(c.TemplateVariants); err != nil {
+        return nil, err
+    }
+    if j.variants, err = encodeJSON(c.Variants, "[]"); err != nil {
+        return nil, err
+    }
+    if j.variables, err = encodeStringMap(c.Variables); err != nil {
+        return nil, err
+    }
+    if j.whatsappParameters, err = encodeJSON(c.WhatsAppParameters, "[]"); err != nil {
+        return nil, err
+    }
+    if c.QuietHours != nil {
+        if j.quietHours, err = encodeJSON(c.QuietHours, "null"); err != nil {
+            return nil, err
+        }
+    }
+    if c.Audience != nil {
+        if j.audience, err = encodeJSON(c.Audience, "null"); err != nil {
+            return nil, err
+        }
+    }
+    return &j, nil
+}
+
+// Update stores an edited campaign. Only draft and scheduled campaigns can be edited, so a
+// campaign that started sending or was deleted since it was loaded returns ErrConflict.
 func (r *CampaignRepository) Update(c *model.Campaign) error {
+    j, err := encodeCampaignJSON(c)
+    if err != nil {
+        return err
+    }
     query := `
         UPDATE campaigns
-        SET name=$1, base_template=$2, status=$3, updated_at=NOW()
-        WHERE id=$4
+        SET name=$1, base_template=$2, status=$3, template_variants=$4, fallback_language=NULLIF($5, ''),
+            variants=$6, variables=$7, whatsapp_template_id=$8, whatsapp_parameters=$9, frequency_cap_policy=$10, quiet_hours=$11,
+            channel=$12, scheduled_at=$13, audience=$14, updated_at=NOW()
+        WHERE id=$15 AND status IN ('draft', 'scheduled') AND deleted_at IS NULL
     `
-    _, err := r.DB.Exec(query, c.Name, c.BaseTemplate, c.Status, c.ID)
-    return err
+    res, err := r.DB.Exec(query, c.Name, c.BaseTemplate, c.Status, j.templateVariants, c.FallbackLanguage,
+        j.variants, j.variables, c.WhatsAppTemplateID, j.whatsappParameters, c.FrequencyCapPolicy, j.quietHours,
+        c.Channel, c.ScheduledAt, j.audience, c.ID)
+    if err != nil {
+        return err
+    }
+    if n, err := res.RowsAffected(); err == nil && n == 0 {
+        return appErrors.NewConflict("status", "campaign can only be edited while draft or scheduled")
+    }
+    return nil
 }
 
-func (r *CampaignRepository) UpdateStatus(campaignID int, status string) error {
-    query := `UPDATE campaigns SET status=$1, updated_at=$2 WHERE id=$3`
-    _, err := r.DB.Exec(query, status, time.Now(), campaignID)
-    return err
+// Delete soft-deletes a draft campaign and reports whether it did
+func (r *CampaignRepository) Delete(id int) (bool, error) {
+    query := `UPDATE campaigns SET deleted_at=NOW(), updated_at=NOW() WHERE id=$1 AND status='draft' AND deleted_at IS NULL`
+    res, err := r.DB.Exec(query, id)
+    if err != nil {
+        return false, err
+    }
+    n, err := res.RowsAffected()
+    return n > 0, err
+}
+
+// TransitionStatus moves a campaign to status to, provided it is still in status from, and
+// reports whether it did. Callers check the transition is legal with campaignstate first.
+func (r *CampaignRepository) TransitionStatus(campaignID int, from, to string) (bool, error) {
+    query := `UPDATE campaigns SET status=$1, updated_at=$2 WHERE id=$3 AND status=$4 AND deleted_at IS NULL`
+    res, err := r.DB.Exec(query, to, time.Now(), campaignID, from)
+    if err != nil {
+        return false, err
+    }
+    n, err := res.RowsAffected()
+    return n > 0, err
+}
+
+// CompleteCampaign moves a sending campaign to its final status and stamps completed_at,
+// reporting whether it did. Only one of several workers finishing at once succeeds.
+func (r *CampaignRepository) CompleteCampaign(campaignID int, status string, completedAt time.Time) (bool, error) {
+    query := `UPDATE campaigns SET status=$1, completed_at=$2, updated_at=$2 WHERE id=$3 AND status='sending'`
+    res, err := r.DB.Exec(query, status, completedAt, campaignID)
+    if err != nil {
+        return false, err
+    }
+    n, err := res.RowsAffected()
+    return n > 0, err
 }
 
 func (r *CampaignRepository) GetByID(id int) (*model.Campaign, error) {
-    query := `
-        SELECT id, name, channel, status, base_template, scheduled_at, created_at, updated_at
-        FROM campaigns WHERE id=$1
-    `
-    var c model.Campaign
-    err := r.DB.QueryRow(query, id).Scan(&c.ID, &c.Name, &c.Channel, &c.Status, &c.BaseTemplate, &c.ScheduledAt, &c.CreatedAt, &c.UpdatedAt)
+    query := `SELECT ` + campaignColumns + ` FROM campaigns WHERE id=$1 AND deleted_at IS NULL`
+    c, err := scanCampaign(r.DB.QueryRow(query, id))
     if err != nil {
         if err == sql.ErrNoRows {
             return nil, appErrors.NewCampaignNotFound(id)
         }
         return nil, err
     }
-    return &c, nil
+    return c, nil
 }
 
 func (r *CampaignRepository) ListCampaigns(offset, limit int, channel, status string) ([]*model.Campaign, int, error) {
     campaigns := []*model.Campaign{}
-    query := `SELECT id, name, channel, status, base_template, scheduled_at, created_at, updated_at FROM campaigns WHERE 1=1`
+    query := `SELECT ` + campaignColumns + ` FROM campaigns WHERE deleted_at IS NULL`
     args := []interface{}{}
     argPos := 1
 
@@ -105,15 +267,15 @@ func (r *CampaignRepository) ListCampaigns(offset, limit int, channel, status st
     defer rows.Close()
 
     for rows.Next() {
-        c := &model.Campaign{}
-        if err := rows.Scan(&c.ID, &c.Name, &c.Channel, &c.Status, &c.BaseTemplate, &c.ScheduledAt, &c.CreatedAt, &c.UpdatedAt); err != nil {
+        c, err := scanCampaign(rows)
+        if err != nil {
             return nil, 0, err
         }
         campaigns = append(campaigns, c)
     }
 
     // Count total
-    countQuery := `SELECT COUNT(*) FROM campaigns WHERE 1=1`
+    countQuery := `SELECT COUNT(*) FROM campaigns WHERE deleted_at IS NULL`
     argsCount := []interface{}{}
     argPosCount := 1
     if channel != "" {
@@ -151,7 +313,7 @@ func (r *CampaignRepository) CreateOutboundMessage(campaignID, customerID int) (
     query := `
         INSERT INTO outbound_messages (campaign_id, customer_id, status, retry_count, created_at)
         VALUES ($1, $2, 'pending', 0, NOW())
-        RETURNING id, status, retry_count, created_at, updated_at,rendered_content
+        RETURNING id, status, retry_count, created_at, updated_at
     `
     var msg model.OutboundMessage
     err = r.DB.QueryRow(query, campaignID, customerID).Scan(&msg.ID, &msg.Status, &msg.RetryCount, &msg.CreatedAt, &msg.UpdatedAt)
@@ -166,13 +328,13 @@ func (r *CampaignRepository) CreateOutboundMessage(campaignID, customerID int) (
 
 
 func (r *CampaignRepository) GetOutboundMessage(campaignID, customerID int) (*model.OutboundMessage, error) {
-    query := `SELECT id, campaign_id, customer_id, status, rendered_content, last_error, retry_count, created_at, updated_at
+    query := `SELECT id, campaign_id, customer_id, status, COALESCE(rendered_content, ''), COALESCE(last_error, ''), retry_count, segments, COALESCE(variant, ''), created_at, updated_at
               FROM outbound_messages
               WHERE campaign_id=$1 AND customer_id=$2`
     var msg model.OutboundMessage
     err := r.DB.QueryRow(query, campaignID, customerID).Scan(
         &msg.ID, &msg.CampaignID, &msg.CustomerID, &msg.Status,
-        &msg.RenderedContent, &msg.LastError, &msg.RetryCount,
+        &msg.RenderedContent, &msg.LastError, &msg.RetryCount, &msg.Segments, &msg.Variant,
         &msg.CreatedAt, &msg.UpdatedAt,
     )
     if err != nil {
@@ -185,11 +347,28 @@ func (r *CampaignRepository) GetOutboundMessage(campaignID, customerID int) (*mo
 }
 
 func (r *CampaignRepository) UpdateOutboundMessageStatus(id int, status, lastError string) error {
-    query := `UPDATE outbound_messages SET status=$1, last_error=$2, retry_count=retry_count+1, updated_at=NOW() WHERE id=$3`
+    // only failed delivery attempts count toward MaxSendRetries; screening outcomes such as
+    // suppressed or held are not attempts
+    query := `UPDATE outbound_messages
+        SET status=$1, last_error=$2, retry_count=retry_count + CASE WHEN $1 IN ('failed', 'retrying') THEN 1 ELSE 0 END, updated_at=NOW(),
+            sent_at=CASE WHEN $1='sent' THEN NOW() ELSE sent_at END
+        WHERE id=$3`
     _, err := r.DB.Exec(query, status, lastError, id)
     return err
 }
 
+// ClaimOutboundMessage moves a pending or retrying message to sending and reports whether
+// it did. Only one of several workers handed the same message gets to deliver it.
+func (r *CampaignRepository) ClaimOutboundMessage(id int) (bool, error) {
+    query := `UPDATE outbound_messages SET status='sending', updated_at=NOW() WHERE id=$1 AND status IN ('pending', 'retrying')`
+    res, err := r.DB.Exec(query, id)
+    if err != nil {
+        return false, err
+    }
+    n, err := res.RowsAffected()
+    return n > 0, err
+}
+
 func (r *CampaignRepository) GetCampaignStats(campaignID int) (map[string]int, error) {
     query := `SELECT status, COUNT(*) FROM outbound_messages WHERE campaign_id=$1 GROUP BY status`
     rows, err := r.DB.Query(query, campaignID)
@@ -223,22 +402,35 @@ func (r *CampaignRepository) Exists(campaignID, customerID int) (bool, error) {
     return count > 0, nil
 }
 
-func (r *CampaignRepository) UpdateOutboundMessageContent(id int, content string) error {
-    query := `UPDATE outbound_messages SET rendered_content=$1, updated_at=NOW() WHERE id=$2`
-    _, err := r.DB.Exec(query, content, id)
+// UpdateOutboundMessageContent stores the rendered output of a message
+func (r *CampaignRepository) UpdateOutboundMessageContent(msg *model.OutboundMessage) error {
+    var templateParameters interface{}
+    if len(msg.TemplateParameters) > 0 {
+        raw, err := encodeJSON(msg.TemplateParameters, "[]")
+        if err != nil {
+            return err
+        }
+        templateParameters = raw
+    }
+    query := `
+        UPDATE outbound_messages
+        SET rendered_content=$1, segments=$2, template_parameters=$3, variant=NULLIF($4, ''), updated_at=NOW()
+        WHERE id=$5
+    `
+    _, err := r.DB.Exec(query, msg.RenderedContent, msg.Segments, templateParameters, msg.Variant, msg.ID)
     return err
 }
 
 func (r *CampaignRepository) GetOutboundMessageByID(id int) (*model.OutboundMessage, error) {
     query := `
-        SELECT id, campaign_id, customer_id, status, rendered_content, last_error, retry_count, created_at, updated_at
+        SELECT id, campaign_id, customer_id, status, COALESCE(rendered_content, ''), COALESCE(last_error, ''), retry_count, segments, COALESCE(variant, ''), created_at, updated_at
         FROM outbound_messages
         WHERE id=$1
     `
     var msg model.OutboundMessage
     err := r.DB.QueryRow(query, id).Scan(
         &msg.ID, &msg.CampaignID, &msg.CustomerID, &msg.Status,
-        &msg.RenderedContent, &msg.LastError, &msg.RetryCount,
+        &msg.RenderedContent, &msg.LastError, &msg.RetryCount, &msg.Segments, &msg.Variant,
         &msg.CreatedAt, &msg.UpdatedAt,
     )
     if err != nil {
@@ -251,4 +443,99 @@ func (r *CampaignRepository) GetOutboundMessageByID(id int) (*model.OutboundMess
 }
 
 
+
+// ====================== Frequency capping & quiet hours ======================
+
+// RecentSends counts the messages each customer was sent on channel since the given time,
+// leaving out excludeCampaignID. With includePending, messages queued since then but not
+// yet sent count too.
+func (r *CampaignRepository) RecentSends(customerIDs []int, channel string, since time.Time, excludeCampaignID int, includePending bool) (map[int]RecentSends, error) {
+    recent := map[int]RecentSends{}
+    if len(customerIDs) == 0 {
+        return recent, nil
+    }
+
+    query := `
+        SELECT m.customer_id, COUNT(*), MIN(COALESCE(m.sent_at, m.created_at))
+        FROM outbound_messages m
+        JOIN campaigns c ON c.id = m.campaign_id
+        WHERE m.customer_id = ANY($1) AND c.channel = $2 AND m.campaign_id <> $4
+          AND ((m.status = 'sent' AND m.sent_at >= $3) OR ($5 AND m.status = 'pending' AND m.created_at >= $3))
+        GROUP BY m.customer_id
+    `
+    rows, err := r.DB.Query(query, pq.Array(customerIDs), channel, since, excludeCampaignID, includePending)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var id int
+        var s RecentSends
+        if err := rows.Scan(&id, &s.Count, &s.Oldest); err != nil {
+            return nil, err
+        }
+        recent[id] = s
+    }
+    return recent, rows.Err()
+}
+
+// HoldOutboundMessage holds a message back until notBefore. status is deferred (frequency cap)
+// or held (quiet hours).
+func (r *CampaignRepository) HoldOutboundMessage(id int, status string, notBefore time.Time, reason string) error {
+    query := `UPDATE outbound_messages SET status=$1, not_before=$2, last_error=$3, updated_at=NOW() WHERE id=$4`
+    _, err := r.DB.Exec(query, status, notBefore, reason, id)
+    return err
+}
+
+// ReleaseDueMessages puts deferred and held messages that are due back to pending and returns their IDs.
+// Messages left sending for StaleDeliveryAfter by a worker that died are released too.
+func (r *CampaignRepository) ReleaseDueMessages(now time.Time) ([]int, error) {
+    query := `
+        UPDATE outbound_messages SET status='pending', not_before=NULL, updated_at=NOW()
+        WHERE (status IN ('deferred', 'held') AND not_before <= $1) OR (status = 'sending' AND updated_at <= $2)
+        RETURNING id
+    `
+    rows, err := r.DB.Query(query, now, now.Add(-StaleDeliveryAfter))
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    ids := []int{}
+    for rows.Next() {
+        var id int
+        if err := rows.Scan(&id); err != nil {
+            return nil, err
+        }
+        ids = append(ids, id)
+    }
+    return ids, rows.Err()
+}
+
+// UpdateCampaignMessageStatuses moves a campaign's messages in any of the from statuses to
+// status to, recording reason as their last error, and returns their IDs
+func (r *CampaignRepository) UpdateCampaignMessageStatuses(campaignID int, from []string, to, reason string) ([]int, error) {
+    query := `
+        UPDATE outbound_messages SET status=$1, last_error=NULLIF($2, ''), not_before=NULL, updated_at=NOW()
+        WHERE campaign_id=$3 AND status = ANY($4)
+        RETURNING id
+    `
+    rows, err := r.DB.Query(query, to, reason, campaignID, pq.Array(from))
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    ids := []int{}
+    for rows.Next() {
+        var id int
+        if err := rows.Scan(&id); err != nil {
+            return nil, err
+        }
+        ids = append(ids, id)
+    }
+    return ids, rows.Err()
+}
+
 var _ CampaignRepositoryInterface = (*CampaignRepository)(nil)
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// schedulerLockKey identifies the Postgres advisory lock held by the replica running a scheduler
+// pass; any value works as long as nothing else locks it ("SCHED" in ASCII)
+const schedulerLockKey int64 = 0x5343484544
+
+type CampaignScheduleRepositoryInterface interface {
+	WithSchedulerLock(fn func() error) (bool, error)
+	DueCampaigns(now time.Time, limit int) ([]int, error)
+	ClaimScheduled(campaignID int, now time.Time) (bool, error)
+	MarkDispatched(campaignID int, now time.Time) error
+}
+
+type CampaignScheduleRepository struct {
+	DB *sql.DB
+}
+
+// WithSchedulerLock runs fn while holding the scheduler's advisory lock and reports whether the
+// lock was free. The lock belongs to a session, so it is taken and released on one connection;
+// if the process dies the session ends and Postgres releases it.
+func (r *CampaignScheduleRepository) WithSchedulerLock(fn func() error) (bool, error) {
+	ctx := context.Background()
+	conn, err := r.DB.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, schedulerLockKey).Scan(&locked); err != nil {
+		return false, err
+	}
+	if !locked {
+		return false, nil
+	}
+	defer conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, schedulerLockKey)
+	return true, fn()
+}
+
+// DueCampaigns lists campaigns whose dispatch started but never finished, then scheduled campaigns
+// whose time has come, oldest first. Campaigns without an audience are left alone.
+func (r *CampaignScheduleRepository) DueCampaigns(now time.Time, limit int) ([]int, error) {
+	query := `
+		SELECT id FROM campaigns
+		WHERE deleted_at IS NULL AND audience IS NOT NULL
+		  AND ((status = 'scheduled' AND scheduled_at <= $1)
+		    OR (status = 'sending' AND dispatch_started_at IS NOT NULL AND dispatched_at IS NULL))
+		ORDER BY (status = 'scheduled'), scheduled_at, id
+		LIMIT $2
+	`
+	rows, err := r.DB.Query(query, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ClaimScheduled moves a scheduled campaign to sending and stamps dispatch_started_at, reporting
+// whether it did. It fails when the campaign was sent, unscheduled or deleted in the meantime.
+func (r *CampaignScheduleRepository) ClaimScheduled(campaignID int, now time.Time) (bool, error) {
+	query := `
+		UPDATE campaigns SET status='sending', dispatch_started_at=$1, updated_at=$1
+		WHERE id=$2 AND status='scheduled' AND deleted_at IS NULL
+	`
+	res, err := r.DB.Exec(query, now, campaignID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// MarkDispatched records that every message of the campaign was created and queued
+func (r *CampaignScheduleRepository) MarkDispatched(campaignID int, now time.Time) error {
+	_, err := r.DB.Exec(`UPDATE campaigns SET dispatched_at=$1 WHERE id=$2`, now, campaignID)
+	return err
+}
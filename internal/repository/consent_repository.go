@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/lib/pq"
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+)
+
+// ConsentRepositoryInterface defines methods used by service
+type ConsentRepositoryInterface interface {
+	Grant(c *model.Consent) error
+	Revoke(customerID int, channel string) (*model.Consent, error)
+	ListByCustomer(customerID int) ([]model.Consent, error)
+	Consented(customerIDs []int, channel string) (map[int]bool, error)
+}
+
+// ConsentRepository is the concrete implementation
+type ConsentRepository struct {
+	DB *sql.DB
+}
+
+const consentColumns = `id, customer_id, channel, source, granted_at, revoked_at`
+
+func scanConsent(row rowScanner) (*model.Consent, error) {
+	var c model.Consent
+	var revokedAt sql.NullTime
+	if err := row.Scan(&c.ID, &c.CustomerID, &c.Channel, &c.Source, &c.GrantedAt, &revokedAt); err != nil {
+		return nil, err
+	}
+	if revokedAt.Valid {
+		c.RevokedAt = &revokedAt.Time
+	}
+	return &c, nil
+}
+
+// Grant records consent. When the customer already holds active consent for the channel
+// the existing record is kept, since it is the earliest proof, and returned in c.
+func (r *ConsentRepository) Grant(c *model.Consent) error {
+	query := `
+        INSERT INTO consents (customer_id, channel, source, granted_at)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (customer_id, channel) WHERE revoked_at IS NULL DO NOTHING
+        RETURNING ` + consentColumns
+	stored, err := scanConsent(r.DB.QueryRow(query, c.CustomerID, c.Channel, c.Source, c.GrantedAt))
+	if err == sql.ErrNoRows {
+		query = `SELECT ` + consentColumns + ` FROM consents WHERE customer_id=$1 AND channel=$2 AND revoked_at IS NULL`
+		stored, err = scanConsent(r.DB.QueryRow(query, c.CustomerID, c.Channel))
+	}
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+		return appErrors.NewCustomerNotFound(c.CustomerID)
+	}
+	if err != nil {
+		return err
+	}
+	*c = *stored
+	return nil
+}
+
+// Revoke ends the customer's active consent for a channel
+func (r *ConsentRepository) Revoke(customerID int, channel string) (*model.Consent, error) {
+	query := `
+        UPDATE consents SET revoked_at = NOW()
+        WHERE customer_id=$1 AND channel=$2 AND revoked_at IS NULL
+        RETURNING ` + consentColumns
+	c, err := scanConsent(r.DB.QueryRow(query, customerID, channel))
+	if err == sql.ErrNoRows {
+		return nil, appErrors.NewConsentNotFound(customerID, channel)
+	}
+	return c, err
+}
+
+// ListByCustomer returns a customer's consent history, newest first
+func (r *ConsentRepository) ListByCustomer(customerID int) ([]model.Consent, error) {
+	query := `SELECT ` + consentColumns + ` FROM consents WHERE customer_id=$1 ORDER BY granted_at DESC, id DESC`
+	rows, err := r.DB.Query(query, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	consents := []model.Consent{}
+	for rows.Next() {
+		c, err := scanConsent(rows)
+		if err != nil {
+			return nil, err
+		}
+		consents = append(consents, *c)
+	}
+	return consents, rows.Err()
+}
+
+// Consented reports which of customerIDs hold active consent for channel
+func (r *ConsentRepository) Consented(customerIDs []int, channel string) (map[int]bool, error) {
+	consented := map[int]bool{}
+	if len(customerIDs) == 0 {
+		return consented, nil
+	}
+
+	query := `
+        SELECT customer_id FROM consents
+        WHERE customer_id = ANY($1) AND channel=$2 AND revoked_at IS NULL
+    `
+	rows, err := r.DB.Query(query, pq.Array(customerIDs), channel)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		consented[id] = true
+	}
+	return consented, rows.Err()
+}
+
+var _ ConsentRepositoryInterface = (*ConsentRepository)(nil)
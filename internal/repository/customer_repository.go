@@ -2,14 +2,30 @@ package repository
 
 import (
 	"database/sql"
+	"fmt"
 
+	"github.com/lib/pq"
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
 	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/tagexpr"
 )
 
 // CustomerRepositoryInterface defines methods used by service
 type CustomerRepositoryInterface interface {
 	GetByID(id int) (*model.Customer, error)
 	ListAll() ([]model.Customer, error)
+	List(offset, limit int, location, product string) ([]model.Customer, int, error)
+	Create(c *model.Customer) error
+	Update(c *model.Customer) error
+	Delete(id int) error
+	UpsertByPhone(c *model.Customer) (created bool, err error)
+	UpdateAttributes(id int, attributes map[string]string) error
+	Messages(customerID int) ([]model.OutboundMessage, error)
+	Erase(id int) error
+	AddTags(customerID int, tags []string) error
+	RemoveTag(customerID int, tag string) (bool, error)
+	Tags(customerID int) ([]string, error)
+	CustomerIDsByTags(expr tagexpr.Expr) ([]int, error)
 }
 
 // CustomerRepository is the concrete implementation
@@ -17,31 +33,55 @@ type CustomerRepository struct {
 	DB *sql.DB
 }
 
-// GetByID fetches a customer by ID
-func (r *CustomerRepository) GetByID(id int) (*model.Customer, error) {
-	query := `
-        SELECT id, phone, first_name, last_name, location, preferred_product
-        FROM customers
-        WHERE id = $1
-    `
-	row := r.DB.QueryRow(query, id)
+const customerColumns = `id, phone, first_name, last_name, location, preferred_product, COALESCE(language, ''), COALESCE(timezone, ''), attributes, erased_at`
 
+// scanCustomer reads a row selected with customerColumns
+func scanCustomer(row rowScanner) (*model.Customer, error) {
 	var c model.Customer
-	if err := row.Scan(&c.ID, &c.Phone, &c.FirstName, &c.LastName, &c.Location, &c.PreferredProduct); err != nil {
+	var attributes []byte
+	var erasedAt sql.NullTime
+	if err := row.Scan(&c.ID, &c.Phone, &c.FirstName, &c.LastName, &c.Location, &c.PreferredProduct, &c.Language, &c.Timezone, &attributes, &erasedAt); err != nil {
+		return nil, err
+	}
+	if erasedAt.Valid {
+		c.ErasedAt = &erasedAt.Time
+	}
+	var err error
+	if c.Attributes, err = decodeStringMap(attributes); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// customerError translates constraint violations into application errors
+func customerError(err error) error {
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Code {
+		case "23505": // unique_violation
+			return appErrors.NewConflict("phone", "is already used by another customer")
+		case "23503": // foreign_key_violation
+			return appErrors.NewConflict("id", "customer has outbound messages and cannot be deleted")
+		}
+	}
+	return err
+}
+
+// GetByID fetches a customer by ID
+func (r *CustomerRepository) GetByID(id int) (*model.Customer, error) {
+	query := `SELECT ` + customerColumns + ` FROM customers WHERE id = $1`
+	c, err := scanCustomer(r.DB.QueryRow(query, id))
+	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // not found
 		}
 		return nil, err
 	}
-	return &c, nil
+	return c, nil
 }
 
 // ListAll fetches all customers (could be used for sending campaigns)
 func (r *CustomerRepository) ListAll() ([]model.Customer, error) {
-	query := `
-        SELECT id, phone, first_name, last_name, location, preferred_product
-        FROM customers
-    `
+	query := `SELECT ` + customerColumns + ` FROM customers`
 	rows, err := r.DB.Query(query)
 	if err != nil {
 		return nil, err
@@ -50,14 +90,234 @@ func (r *CustomerRepository) ListAll() ([]model.Customer, error) {
 
 	customers := []model.Customer{}
 	for rows.Next() {
-		var c model.Customer
-		if err := rows.Scan(&c.ID, &c.Phone, &c.FirstName, &c.LastName, &c.Location, &c.PreferredProduct); err != nil {
+		c, err := scanCustomer(rows)
+		if err != nil {
 			return nil, err
 		}
-		customers = append(customers, c)
+		customers = append(customers, *c)
 	}
 	return customers, nil
 }
 
+// List returns a page of customers, optionally filtered by location and preferred product,
+// plus the total number of matches
+func (r *CustomerRepository) List(offset, limit int, location, product string) ([]model.Customer, int, error) {
+	where := ` WHERE 1=1`
+	args := []interface{}{}
+	if location != "" {
+		args = append(args, location)
+		where += fmt.Sprintf(" AND location=$%d", len(args))
+	}
+	if product != "" {
+		args = append(args, product)
+		where += fmt.Sprintf(" AND preferred_product=$%d", len(args))
+	}
+
+	var total int
+	if err := r.DB.QueryRow(`SELECT COUNT(*) FROM customers`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + customerColumns + ` FROM customers` + where +
+		fmt.Sprintf(" ORDER BY id LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	rows, err := r.DB.Query(query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	customers := []model.Customer{}
+	for rows.Next() {
+		c, err := scanCustomer(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		customers = append(customers, *c)
+	}
+	return customers, total, rows.Err()
+}
+
+// Create inserts a customer; a duplicate phone returns a conflict error
+func (r *CustomerRepository) Create(c *model.Customer) error {
+	attributes, err := encodeStringMap(c.Attributes)
+	if err != nil {
+		return err
+	}
+	query := `
+        INSERT INTO customers (phone, first_name, last_name, location, preferred_product, language, timezone, attributes)
+        VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), $8)
+        RETURNING id
+    `
+	err = r.DB.QueryRow(query, c.Phone, c.FirstName, c.LastName, c.Location, c.PreferredProduct, c.Language, c.Timezone, attributes).Scan(&c.ID)
+	return customerError(err)
+}
+
+// Update overwrites every field of a customer
+func (r *CustomerRepository) Update(c *model.Customer) error {
+	attributes, err := encodeStringMap(c.Attributes)
+	if err != nil {
+		return err
+	}
+	query := `
+        UPDATE customers
+        SET phone=$1, first_name=$2, last_name=$3, location=$4, preferred_product=$5, language=NULLIF($6, ''),
+            timezone=NULLIF($7, ''), attributes=$8
+        WHERE id=$9
+    `
+	_, err = r.DB.Exec(query, c.Phone, c.FirstName, c.LastName, c.Location, c.PreferredProduct, c.Language, c.Timezone, attributes, c.ID)
+	return customerError(err)
+}
+
+// UpsertByPhone inserts a customer or updates the one with the same phone. Blank fields
+// keep their stored value and attributes are merged. created reports whether a new row
+// was inserted.
+func (r *CustomerRepository) UpsertByPhone(c *model.Customer) (bool, error) {
+	return upsertCustomer(r.DB, c)
+}
 
+// rowQueryer is satisfied by both *sql.DB and *sql.Tx
+type rowQueryer interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// upsertCustomer runs the UpsertByPhone statement on db or inside a transaction
+func upsertCustomer(q rowQueryer, c *model.Customer) (bool, error) {
+	attributes, err := encodeStringMap(c.Attributes)
+	if err != nil {
+		return false, err
+	}
+	query := `
+        INSERT INTO customers (phone, first_name, last_name, location, preferred_product, language, timezone, attributes)
+        VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), $8)
+        ON CONFLICT (phone) DO UPDATE SET
+            first_name = COALESCE(NULLIF(EXCLUDED.first_name, ''), customers.first_name),
+            last_name = COALESCE(NULLIF(EXCLUDED.last_name, ''), customers.last_name),
+            location = COALESCE(NULLIF(EXCLUDED.location, ''), customers.location),
+            preferred_product = COALESCE(NULLIF(EXCLUDED.preferred_product, ''), customers.preferred_product),
+            language = COALESCE(EXCLUDED.language, customers.language),
+            timezone = COALESCE(EXCLUDED.timezone, customers.timezone),
+            attributes = customers.attributes || EXCLUDED.attributes
+        RETURNING id, (xmax = 0)
+    `
+	var created bool
+	err = q.QueryRow(query, c.Phone, c.FirstName, c.LastName, c.Location, c.PreferredProduct, c.Language, c.Timezone, attributes).
+		Scan(&c.ID, &created)
+	return created, err
+}
+
+// Delete removes a customer. Customers that already have outbound messages cannot be deleted.
+func (r *CustomerRepository) Delete(id int) error {
+	res, err := r.DB.Exec(`DELETE FROM customers WHERE id = $1`, id)
+	if err != nil {
+		return customerError(err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return appErrors.NewCustomerNotFound(id)
+	}
+	return nil
+}
+
+// UpdateAttributes merges the given keys into the customer's attributes.
+// A key with an empty value is removed.
+func (r *CustomerRepository) UpdateAttributes(id int, attributes map[string]string) error {
+	set := map[string]string{}
+	remove := []string{}
+	for k, v := range attributes {
+		if v == "" {
+			remove = append(remove, k)
+			continue
+		}
+		set[k] = v
+	}
+
+	raw, err := encodeStringMap(set)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE customers SET attributes = (attributes || $1::jsonb) - $2::text[] WHERE id = $3`
+	_, err = r.DB.Exec(query, raw, pq.Array(remove), id)
+	return err
+}
+
+// Messages returns every outbound message sent to a customer, oldest first
+func (r *CustomerRepository) Messages(customerID int) ([]model.OutboundMessage, error) {
+	query := `
+        SELECT id, campaign_id, customer_id, status, COALESCE(rendered_content, ''), template_parameters,
+            COALESCE(variant, ''), COALESCE(last_error, ''), retry_count, segments, created_at, updated_at
+        FROM outbound_messages
+        WHERE customer_id = $1
+        ORDER BY created_at, id
+    `
+	rows, err := r.DB.Query(query, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []model.OutboundMessage{}
+	for rows.Next() {
+		var msg model.OutboundMessage
+		var parameters []byte
+		err := rows.Scan(&msg.ID, &msg.CampaignID, &msg.CustomerID, &msg.Status, &msg.RenderedContent, &parameters,
+			&msg.Variant, &msg.LastError, &msg.RetryCount, &msg.Segments, &msg.CreatedAt, &msg.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if err := decodeJSON(parameters, &msg.TemplateParameters); err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+	return messages, rows.Err()
+}
+
+// Erase anonymizes a customer in place. Personal fields are blanked, the phone is replaced
+// by a placeholder, message content, click details and the phone in import reports are
+// scrubbed, tags are removed, unsent messages are suppressed and active consents are revoked. Delivered message statuses, segments and variants are kept so campaign stats still add up.
+func (r *CustomerRepository) Erase(id int) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+        UPDATE customers
+        SET phone = 'erased-' || id, first_name = '', last_name = '', location = '', preferred_product = '',
+            language = NULL, timezone = NULL, attributes = '{}'::jsonb, erased_at = NOW()
+        WHERE id = $1
+    `, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return appErrors.NewCustomerNotFound(id)
+	}
+
+	// messages still waiting to be sent are never sent
+	if _, err := tx.Exec(`
+        UPDATE outbound_messages
+        SET rendered_content = NULL, template_parameters = NULL, last_error = NULL, updated_at = NOW(),
+            status = CASE WHEN status = ANY($2) THEN 'suppressed' ELSE status END
+        WHERE customer_id = $1
+    `, id, pq.Array(UnfinishedMessageStatuses)); err != nil {
+		return err
+	}
+
+	statements := []string{
+		`UPDATE link_clicks SET user_agent = NULL, ip_address = NULL
+            WHERE short_link_id IN (SELECT s.id FROM short_links s JOIN outbound_messages m ON m.id = s.outbound_message_id WHERE m.customer_id = $1)`,
+		`UPDATE consents SET revoked_at = NOW() WHERE customer_id = $1 AND revoked_at IS NULL`,
+		`UPDATE import_job_rows SET phone = NULL WHERE customer_id = $1`,
+		`DELETE FROM customer_tags WHERE customer_id = $1`,
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
 
+var _ CustomerRepositoryInterface = (*CustomerRepository)(nil)
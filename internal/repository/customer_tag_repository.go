@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/unclebandit/smsleopard-backend/internal/tagexpr"
+)
+
+// compileTagExpr turns a tag expression into a WHERE clause over customers, one EXISTS
+// subquery per tag. Tags are always passed as query arguments.
+func compileTagExpr(expr tagexpr.Expr, args *[]interface{}) (string, error) {
+	switch e := expr.(type) {
+	case tagexpr.Tag:
+		*args = append(*args, string(e))
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM customer_tags t WHERE t.customer_id = customers.id AND t.tag = $%d)", len(*args)), nil
+	case tagexpr.Not:
+		x, err := compileTagExpr(e.X, args)
+		if err != nil {
+			return "", err
+		}
+		return "NOT " + x, nil
+	case tagexpr.And, tagexpr.Or:
+		var l, r tagexpr.Expr
+		op := " AND "
+		if and, ok := e.(tagexpr.And); ok {
+			l, r = and.L, and.R
+		} else {
+			or := e.(tagexpr.Or)
+			l, r, op = or.L, or.R, " OR "
+		}
+		left, err := compileTagExpr(l, args)
+		if err != nil {
+			return "", err
+		}
+		right, err := compileTagExpr(r, args)
+		if err != nil {
+			return "", err
+		}
+		return "(" + left + op + right + ")", nil
+	}
+	return "", fmt.Errorf("unknown tag expression %T", expr)
+}
+
+// AddTags attaches tags to a customer; tags it already has are left alone
+func (r *CustomerRepository) AddTags(customerID int, tags []string) error {
+	query := `
+        INSERT INTO customer_tags (customer_id, tag)
+        SELECT $1, unnest($2::text[])
+        ON CONFLICT (customer_id, tag) DO NOTHING
+    `
+	_, err := r.DB.Exec(query, customerID, pq.Array(tags))
+	return err
+}
+
+// RemoveTag detaches a tag from a customer and reports whether it was there
+func (r *CustomerRepository) RemoveTag(customerID int, tag string) (bool, error) {
+	res, err := r.DB.Exec(`DELETE FROM customer_tags WHERE customer_id = $1 AND tag = $2`, customerID, tag)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Tags lists a customer's tags in alphabetical order
+func (r *CustomerRepository) Tags(customerID int) ([]string, error) {
+	rows, err := r.DB.Query(`SELECT tag FROM customer_tags WHERE customer_id = $1 ORDER BY tag`, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []string{}
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// CustomerIDsByTags returns the IDs of the non-erased customers matching the expression, in ID order
+func (r *CustomerRepository) CustomerIDsByTags(expr tagexpr.Expr) ([]int, error) {
+	var args []interface{}
+	where, err := compileTagExpr(expr, &args)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.DB.Query(`SELECT id FROM customers WHERE erased_at IS NULL AND `+where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+)
+
+// ImportJobRepositoryInterface defines methods used by service
+type ImportJobRepositoryInterface interface {
+	Create(job *model.ImportJob) error
+	Finish(job *model.ImportJob) error
+	SaveBatch(jobID int, rows []model.ImportRow, customers []*model.Customer) error
+	GetByID(id int) (*model.ImportJob, error)
+	ListRows(jobID int, outcome string, offset, limit int) ([]model.ImportRow, int, error)
+}
+
+// ImportJobRepository is the concrete implementation
+type ImportJobRepository struct {
+	DB *sql.DB
+}
+
+// Create starts a job in the running state
+func (r *ImportJobRepository) Create(job *model.ImportJob) error {
+	job.Status = "running"
+	query := `INSERT INTO import_jobs (filename, status) VALUES ($1, $2) RETURNING id, created_at`
+	return r.DB.QueryRow(query, job.Filename, job.Status).Scan(&job.ID, &job.CreatedAt)
+}
+
+// Finish stores the final status and counts of a job
+func (r *ImportJobRepository) Finish(job *model.ImportJob) error {
+	query := `
+        UPDATE import_jobs
+        SET status=$1, total_rows=$2, accepted=$3, updated=$4, rejected=$5, error=NULLIF($6, ''), completed_at=NOW()
+        WHERE id=$7
+        RETURNING completed_at
+    `
+	return r.DB.QueryRow(query, job.Status, job.TotalRows, job.Accepted, job.Updated, job.Rejected, job.Error, job.ID).
+		Scan(&job.CompletedAt)
+}
+
+// SaveBatch upserts the customers parsed from a batch of CSV rows by phone and records
+// every row's outcome in one transaction, adding the batch to the job's counts so a
+// running job shows its progress. customers[i] belongs to rows[i] and is nil for a
+// rejected row; the outcome and customer ID of the other rows are filled in. Nothing is
+// saved when a statement fails.
+func (r *ImportJobRepository) SaveBatch(jobID int, rows []model.ImportRow, customers []*model.Customer) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	counts := map[string]int{}
+	values := make([]string, len(rows))
+	args := []interface{}{jobID}
+	for i := range rows {
+		row := &rows[i]
+		if c := customers[i]; c != nil {
+			created, err := upsertCustomer(tx, c)
+			if err != nil {
+				return err
+			}
+			row.CustomerID = &c.ID
+			row.Outcome = "updated"
+			if created {
+				row.Outcome = "accepted"
+			}
+		}
+		counts[row.Outcome]++
+
+		n := len(args)
+		values[i] = fmt.Sprintf("($1, $%d, NULLIF($%d, ''), $%d, NULLIF($%d, ''), $%d)", n+1, n+2, n+3, n+4, n+5)
+		args = append(args, row.RowNumber, row.Phone, row.Outcome, row.Reason, row.CustomerID)
+	}
+
+	query := `INSERT INTO import_job_rows (job_id, row_number, phone, outcome, reason, customer_id) VALUES ` +
+		strings.Join(values, ", ")
+	if _, err := tx.Exec(query, args...); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+        UPDATE import_jobs
+        SET total_rows = total_rows + $1, accepted = accepted + $2, updated = updated + $3, rejected = rejected + $4
+        WHERE id = $5
+    `, len(rows), counts["accepted"], counts["updated"], counts["rejected"], jobID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetByID fetches a job by ID
+func (r *ImportJobRepository) GetByID(id int) (*model.ImportJob, error) {
+	query := `
+        SELECT id, filename, status, total_rows, accepted, updated, rejected, COALESCE(error, ''), created_at, completed_at
+        FROM import_jobs
+        WHERE id = $1
+    `
+	var job model.ImportJob
+	err := r.DB.QueryRow(query, id).Scan(&job.ID, &job.Filename, &job.Status, &job.TotalRows, &job.Accepted,
+		&job.Updated, &job.Rejected, &job.Error, &job.CreatedAt, &job.CompletedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, appErrors.NewImportJobNotFound(id)
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ListRows returns a page of a job's row outcomes in file order, optionally filtered by
+// outcome, plus the total number of matches
+func (r *ImportJobRepository) ListRows(jobID int, outcome string, offset, limit int) ([]model.ImportRow, int, error) {
+	where := ` WHERE job_id=$1`
+	args := []interface{}{jobID}
+	if outcome != "" {
+		args = append(args, outcome)
+		where += fmt.Sprintf(" AND outcome=$%d", len(args))
+	}
+
+	var total int
+	if err := r.DB.QueryRow(`SELECT COUNT(*) FROM import_job_rows`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT row_number, COALESCE(phone, ''), outcome, COALESCE(reason, ''), customer_id FROM import_job_rows` + where +
+		fmt.Sprintf(" ORDER BY row_number LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	rows, err := r.DB.Query(query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	result := []model.ImportRow{}
+	for rows.Next() {
+		var row model.ImportRow
+		if err := rows.Scan(&row.RowNumber, &row.Phone, &row.Outcome, &row.Reason, &row.CustomerID); err != nil {
+			return nil, 0, err
+		}
+		result = append(result, row)
+	}
+	return result, total, rows.Err()
+}
+
+var _ ImportJobRepositoryInterface = (*ImportJobRepository)(nil)
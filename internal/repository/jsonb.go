@@ -0,0 +1,58 @@
+package repository
+
+import "encoding/json"
+
+// encodeStringMap serializes a map for a JSONB column, storing nil as an empty object
+func encodeStringMap(m map[string]string) (string, error) {
+	if m == nil {
+		return "{}", nil
+	}
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
+
+// decodeStringMap turns a JSONB object into string values; non-string JSON
+// values (numbers, booleans) keep their JSON text form
+func decodeStringMap(raw []byte) (map[string]string, error) {
+	m := map[string]string{}
+	if len(raw) == 0 {
+		return m, nil
+	}
+
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil, err
+	}
+	for k, v := range values {
+		var s string
+		if err := json.Unmarshal(v, &s); err == nil {
+			m[k] = s
+			continue
+		}
+		m[k] = string(v)
+	}
+	return m, nil
+}
+
+// encodeJSON serializes v for a JSONB column, storing nil values as empty
+func encodeJSON(v interface{}, empty string) (string, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	if string(raw) == "null" {
+		return empty, nil
+	}
+	return string(raw), nil
+}
+
+// decodeJSON unmarshals a JSONB column, leaving v untouched when the column is NULL
+func decodeJSON(raw []byte, v interface{}) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, v)
+}
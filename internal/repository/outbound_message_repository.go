@@ -21,8 +21,8 @@ func (r *OutboundMessageRepository) Create(msg *model.OutboundMessage) error {
 
     query := `
         INSERT INTO outbound_messages 
-        (campaign_id, customer_id, status, rendered_content, last_error, retry_count, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        (campaign_id, customer_id, status, rendered_content, last_error, retry_count, segments, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
         RETURNING id
     `
     return r.DB.QueryRow(
@@ -33,6 +33,7 @@ func (r *OutboundMessageRepository) Create(msg *model.OutboundMessage) error {
         msg.RenderedContent,
         msg.LastError,
         msg.RetryCount,
+        msg.Segments,
         msg.CreatedAt,
         msg.UpdatedAt,
     ).Scan(&msg.ID)
@@ -43,17 +44,18 @@ func (r *OutboundMessageRepository) Update(msg *model.OutboundMessage) error {
     msg.UpdatedAt = time.Now()
     query := `
         UPDATE outbound_messages
-        SET status=$1, last_error=$2, retry_count=$3, updated_at=$4
-        WHERE id=$5
+        SET status=$1, last_error=$2, retry_count=$3, rendered_content=$4, segments=$5, updated_at=$6,
+            sent_at=CASE WHEN $1='sent' THEN $6 ELSE sent_at END
+        WHERE id=$7
     `
-    _, err := r.DB.Exec(query, msg.Status, msg.LastError, msg.RetryCount, msg.UpdatedAt, msg.ID)
+    _, err := r.DB.Exec(query, msg.Status, msg.LastError, msg.RetryCount, msg.RenderedContent, msg.Segments, msg.UpdatedAt, msg.ID)
     return err
 }
 
 // GetByID fetches an outbound message by its ID
 func (r *OutboundMessageRepository) GetByID(id int) (*model.OutboundMessage, error) {
     query := `
-        SELECT id, campaign_id, customer_id, status, rendered_content, last_error, retry_count, created_at, updated_at
+        SELECT id, campaign_id, customer_id, status, COALESCE(rendered_content, ''), COALESCE(last_error, ''), retry_count, segments, COALESCE(variant, ''), created_at, updated_at
         FROM outbound_messages
         WHERE id=$1
     `
@@ -66,6 +68,8 @@ func (r *OutboundMessageRepository) GetByID(id int) (*model.OutboundMessage, err
         &msg.RenderedContent,
         &msg.LastError,
         &msg.RetryCount,
+        &msg.Segments,
+        &msg.Variant,
         &msg.CreatedAt,
         &msg.UpdatedAt,
     )
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+)
+
+// SegmentRepositoryInterface defines methods used by service
+type SegmentRepositoryInterface interface {
+	Create(s *model.Segment) error
+	GetByID(id int) (*model.Segment, error)
+	List() ([]model.Segment, error)
+	CountCustomers(rules []model.SegmentRule) (int, error)
+	CustomerIDs(rules []model.SegmentRule) ([]int, error)
+}
+
+// SegmentRepository is the concrete implementation
+type SegmentRepository struct {
+	DB *sql.DB
+}
+
+// SegmentFields maps the customer fields rules may filter on, besides attr.<key>, to their columns
+var SegmentFields = map[string]string{
+	"first_name":        "first_name",
+	"last_name":         "last_name",
+	"location":          "location",
+	"preferred_product": "preferred_product",
+	"language":          "language",
+}
+
+// attributePrefix selects a custom attribute in a rule field, e.g. attr.loyalty_tier
+const attributePrefix = "attr."
+
+// compileRules turns segment rules into a WHERE clause over customers. Rules are
+// combined with AND; values are always passed as query arguments.
+func compileRules(rules []model.SegmentRule) (string, []interface{}, error) {
+	if len(rules) == 0 {
+		return "TRUE", nil, nil
+	}
+
+	var conditions []string
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	for _, rule := range rules {
+		var column string
+		if key, ok := strings.CutPrefix(rule.Field, attributePrefix); ok {
+			column = "(attributes->>" + arg(key) + ")"
+		} else if c, ok := SegmentFields[rule.Field]; ok {
+			column = c
+		} else {
+			return "", nil, fmt.Errorf("unknown segment field %q", rule.Field)
+		}
+		text := "COALESCE(" + column + ", '')"
+
+		switch rule.Op {
+		case "eq":
+			conditions = append(conditions, text+" = "+arg(rule.Value))
+		case "neq":
+			conditions = append(conditions, text+" <> "+arg(rule.Value))
+		case "in":
+			conditions = append(conditions, text+" = ANY("+arg(pq.Array(rule.Values))+")")
+		case "not_in":
+			conditions = append(conditions, text+" <> ALL("+arg(pq.Array(rule.Values))+")")
+		case "gt", "gte", "lt", "lte":
+			op := map[string]string{"gt": ">", "gte": ">=", "lt": "<", "lte": "<="}[rule.Op]
+			// non-numeric values never match instead of failing the cast
+			numeric := "CASE WHEN " + column + " ~ '^-?[0-9]+(\\.[0-9]+)?$' THEN " + column + "::numeric END"
+			conditions = append(conditions, numeric+" "+op+" "+arg(rule.Value)+"::numeric")
+		default:
+			return "", nil, fmt.Errorf("unknown segment operator %q", rule.Op)
+		}
+	}
+	return strings.Join(conditions, " AND "), args, nil
+}
+
+// Create stores a segment
+func (r *SegmentRepository) Create(s *model.Segment) error {
+	rules, err := encodeJSON(s.Rules, "[]")
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO segments (name, rules) VALUES ($1, $2) RETURNING id, created_at`
+	return r.DB.QueryRow(query, s.Name, rules).Scan(&s.ID, &s.CreatedAt)
+}
+
+func scanSegment(row rowScanner) (*model.Segment, error) {
+	var s model.Segment
+	var rules []byte
+	if err := row.Scan(&s.ID, &s.Name, &rules, &s.CreatedAt); err != nil {
+		return nil, err
+	}
+	s.Rules = []model.SegmentRule{}
+	if err := decodeJSON(rules, &s.Rules); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetByID fetches a segment by ID
+func (r *SegmentRepository) GetByID(id int) (*model.Segment, error) {
+	query := `SELECT id, name, rules, created_at FROM segments WHERE id = $1`
+	s, err := scanSegment(r.DB.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, appErrors.NewSegmentNotFound(id)
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+// List returns every segment ordered by name
+func (r *SegmentRepository) List() ([]model.Segment, error) {
+	rows, err := r.DB.Query(`SELECT id, name, rules, created_at FROM segments ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	segments := []model.Segment{}
+	for rows.Next() {
+		s, err := scanSegment(rows)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, *s)
+	}
+	return segments, rows.Err()
+}
+
+// CountCustomers returns how many customers match the rules. Erased customers never match.
+func (r *SegmentRepository) CountCustomers(rules []model.SegmentRule) (int, error) {
+	where, args, err := compileRules(rules)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	err = r.DB.QueryRow(`SELECT COUNT(*) FROM customers WHERE erased_at IS NULL AND (`+where+`)`, args...).Scan(&count)
+	return count, err
+}
+
+// CustomerIDs returns the IDs of the customers matching the rules, in ID order. Erased
+// customers never match.
+func (r *SegmentRepository) CustomerIDs(rules []model.SegmentRule) ([]int, error) {
+	where, args, err := compileRules(rules)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.DB.Query(`SELECT id FROM customers WHERE erased_at IS NULL AND (`+where+`) ORDER BY id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+var _ SegmentRepositoryInterface = (*SegmentRepository)(nil)
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"database/sql"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+)
+
+// ShortLinkRepositoryInterface defines methods used by service
+type ShortLinkRepositoryInterface interface {
+	GetOrCreate(messageID int, target, code string) (*model.ShortLink, error)
+	GetByCode(code string) (*model.ShortLink, error)
+	RecordClick(linkID int, userAgent, ipAddress string) error
+}
+
+// ShortLinkRepository is the concrete implementation
+type ShortLinkRepository struct {
+	DB *sql.DB
+}
+
+// GetOrCreate returns the message's link for target, creating it with code if it does
+// not exist yet. Re-rendering a message therefore keeps its original short URL.
+func (r *ShortLinkRepository) GetOrCreate(messageID int, target, code string) (*model.ShortLink, error) {
+	query := `
+        INSERT INTO short_links (code, outbound_message_id, target_url)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (outbound_message_id, target_url) DO UPDATE SET target_url = EXCLUDED.target_url
+        RETURNING id, code, outbound_message_id, target_url, created_at
+    `
+	var l model.ShortLink
+	err := r.DB.QueryRow(query, code, messageID, target).
+		Scan(&l.ID, &l.Code, &l.OutboundMessageID, &l.TargetURL, &l.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// GetByCode fetches a link by its short code
+func (r *ShortLinkRepository) GetByCode(code string) (*model.ShortLink, error) {
+	query := `SELECT id, code, outbound_message_id, target_url, created_at FROM short_links WHERE code = $1`
+	var l model.ShortLink
+	err := r.DB.QueryRow(query, code).Scan(&l.ID, &l.Code, &l.OutboundMessageID, &l.TargetURL, &l.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, appErrors.NewShortLinkNotFound(code)
+		}
+		return nil, err
+	}
+	return &l, nil
+}
+
+// RecordClick stores one click on a link
+func (r *ShortLinkRepository) RecordClick(linkID int, userAgent, ipAddress string) error {
+	query := `INSERT INTO link_clicks (short_link_id, user_agent, ip_address) VALUES ($1, NULLIF($2, ''), NULLIF($3, ''))`
+	_, err := r.DB.Exec(query, linkID, userAgent, ipAddress)
+	return err
+}
+
+var _ ShortLinkRepositoryInterface = (*ShortLinkRepository)(nil)
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+)
+
+// SuppressionRepositoryInterface defines methods used by service
+type SuppressionRepositoryInterface interface {
+	Create(s *model.Suppression) error
+	Delete(id int) error
+	List(customerID int, channel string, offset, limit int) ([]model.Suppression, int, error)
+	Suppressed(customerIDs []int, channel string) (map[int]string, error)
+}
+
+// SuppressionRepository is the concrete implementation
+type SuppressionRepository struct {
+	DB *sql.DB
+}
+
+// Create adds a customer to the suppression list. Suppressing an already suppressed
+// customer and channel again replaces the reason and keeps the original timestamp.
+func (r *SuppressionRepository) Create(s *model.Suppression) error {
+	query := `
+        INSERT INTO suppressions (customer_id, channel, reason)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (customer_id, channel) DO UPDATE SET reason = EXCLUDED.reason
+        RETURNING id, created_at
+    `
+	err := r.DB.QueryRow(query, s.CustomerID, s.Channel, s.Reason).Scan(&s.ID, &s.CreatedAt)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+		return appErrors.NewCustomerNotFound(s.CustomerID)
+	}
+	return err
+}
+
+// Delete removes an entry, letting the customer be messaged again
+func (r *SuppressionRepository) Delete(id int) error {
+	res, err := r.DB.Exec(`DELETE FROM suppressions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return appErrors.NewSuppressionNotFound(id)
+	}
+	return nil
+}
+
+// List returns a page of entries, newest first, optionally for one customer and channel
+func (r *SuppressionRepository) List(customerID int, channel string, offset, limit int) ([]model.Suppression, int, error) {
+	where := ` WHERE 1=1`
+	args := []interface{}{}
+	if customerID != 0 {
+		args = append(args, customerID)
+		where += fmt.Sprintf(" AND customer_id=$%d", len(args))
+	}
+	if channel != "" {
+		args = append(args, channel)
+		where += fmt.Sprintf(" AND channel=$%d", len(args))
+	}
+
+	var total int
+	if err := r.DB.QueryRow(`SELECT COUNT(*) FROM suppressions`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT id, customer_id, channel, reason, created_at FROM suppressions` + where +
+		fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	rows, err := r.DB.Query(query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	suppressions := []model.Suppression{}
+	for rows.Next() {
+		var s model.Suppression
+		if err := rows.Scan(&s.ID, &s.CustomerID, &s.Channel, &s.Reason, &s.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		suppressions = append(suppressions, s)
+	}
+	return suppressions, total, rows.Err()
+}
+
+// Suppressed returns the customers among customerIDs that may not be messaged on channel,
+// mapped to the reason they were suppressed
+func (r *SuppressionRepository) Suppressed(customerIDs []int, channel string) (map[int]string, error) {
+	suppressed := map[int]string{}
+	if len(customerIDs) == 0 {
+		return suppressed, nil
+	}
+
+	query := `
+        SELECT customer_id, reason
+        FROM suppressions
+        WHERE customer_id = ANY($1) AND channel IN ($2, 'all')
+    `
+	rows, err := r.DB.Query(query, pq.Array(customerIDs), channel)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var reason string
+		if err := rows.Scan(&id, &reason); err != nil {
+			return nil, err
+		}
+		suppressed[id] = reason
+	}
+	return suppressed, rows.Err()
+}
+
+var _ SuppressionRepositoryInterface = (*SuppressionRepository)(nil)
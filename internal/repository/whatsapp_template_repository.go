@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"database/sql"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+)
+
+// WhatsAppTemplateRepositoryInterface defines methods used by service
+type WhatsAppTemplateRepositoryInterface interface {
+	Create(t *model.WhatsAppTemplate) error
+	GetByID(id int) (*model.WhatsAppTemplate, error)
+	List() ([]model.WhatsAppTemplate, error)
+}
+
+// WhatsAppTemplateRepository is the concrete implementation
+type WhatsAppTemplateRepository struct {
+	DB *sql.DB
+}
+
+const whatsappTemplateColumns = `id, name, language, body, parameter_count,
+        COALESCE(header_media_type, ''), COALESCE(header_media_url, ''), created_at`
+
+func scanWhatsAppTemplate(row rowScanner) (*model.WhatsAppTemplate, error) {
+	var t model.WhatsAppTemplate
+	err := row.Scan(&t.ID, &t.Name, &t.Language, &t.Body, &t.ParameterCount, &t.HeaderMediaType, &t.HeaderMediaURL, &t.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Create registers an approved template
+func (r *WhatsAppTemplateRepository) Create(t *model.WhatsAppTemplate) error {
+	query := `
+        INSERT INTO whatsapp_templates (name, language, body, parameter_count, header_media_type, header_media_url)
+        VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''))
+        RETURNING id, created_at
+    `
+	return r.DB.QueryRow(query, t.Name, t.Language, t.Body, t.ParameterCount, t.HeaderMediaType, t.HeaderMediaURL).
+		Scan(&t.ID, &t.CreatedAt)
+}
+
+// GetByID fetches a template by ID
+func (r *WhatsAppTemplateRepository) GetByID(id int) (*model.WhatsAppTemplate, error) {
+	query := `SELECT ` + whatsappTemplateColumns + ` FROM whatsapp_templates WHERE id = $1`
+	t, err := scanWhatsAppTemplate(r.DB.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, appErrors.NewWhatsAppTemplateNotFound(id)
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+// List returns every registered template ordered by name and language
+func (r *WhatsAppTemplateRepository) List() ([]model.WhatsAppTemplate, error) {
+	query := `SELECT ` + whatsappTemplateColumns + ` FROM whatsapp_templates ORDER BY name, language`
+	rows, err := r.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []model.WhatsAppTemplate{}
+	for rows.Next() {
+		t, err := scanWhatsAppTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *t)
+	}
+	return templates, rows.Err()
+}
+
+var _ WhatsAppTemplateRepositoryInterface = (*WhatsAppTemplateRepository)(nil)
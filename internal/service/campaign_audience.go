@@ -0,0 +1,82 @@
+// internal/service/campaign_audience.go
+package service
+
+import (
+	"fmt"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+)
+
+// validateAudience checks that a stored audience names at most one target and that its tag
+// expression parses. A nil audience is valid; the campaign is then sent to explicit customer IDs.
+func validateAudience(a *model.CampaignAudience) error {
+	if a == nil {
+		return nil
+	}
+	targets := 0
+	if len(a.CustomerIDs) > 0 {
+		targets++
+	}
+	if a.SegmentID != nil {
+		targets++
+	}
+	if a.TagExpression != "" {
+		targets++
+		if _, err := ParseTagExpression(a.TagExpression); err != nil {
+			return err
+		}
+	}
+	if targets > 1 {
+		return appErrors.NewValidation("audience", "set only one of customer_ids, segment_id or tag_expression")
+	}
+	return nil
+}
+
+// audienceOrNil treats an audience without any target as no audience, so `"audience": {}`
+// clears it
+func audienceOrNil(a *model.CampaignAudience) *model.CampaignAudience {
+	if a == nil || (len(a.CustomerIDs) == 0 && a.SegmentID == nil && a.TagExpression == "") {
+		return nil
+	}
+	return a
+}
+
+// audienceCustomerIDs resolves a stored audience to customer IDs. Segments and tag expressions
+// are evaluated now, so customers who joined them since the campaign was set up are included.
+func (s *CampaignService) audienceCustomerIDs(a *model.CampaignAudience) ([]int, error) {
+	switch {
+	case a == nil:
+		return nil, appErrors.NewValidation("audience", "campaign has no audience")
+	case a.SegmentID != nil:
+		if s.SegmentRepo == nil {
+			return nil, fmt.Errorf("segments are not configured")
+		}
+		seg, err := s.SegmentRepo.GetByID(*a.SegmentID)
+		if err != nil {
+			return nil, err
+		}
+		return s.SegmentRepo.CustomerIDs(seg.Rules)
+	case a.TagExpression != "":
+		e, err := ParseTagExpression(a.TagExpression)
+		if err != nil {
+			return nil, err
+		}
+		return s.CustomerRepo.CustomerIDsByTags(e)
+	default:
+		return a.CustomerIDs, nil
+	}
+}
+
+// SendCampaignToAudience sends the campaign to its stored audience
+func (s *CampaignService) SendCampaignToAudience(campaignID int) (*SendCampaignResult, error) {
+	campaign, err := s.CampaignRepo.GetByID(campaignID)
+	if err != nil {
+		return nil, err
+	}
+	customerIDs, err := s.audienceCustomerIDs(campaign.Audience)
+	if err != nil {
+		return nil, err
+	}
+	return s.SendCampaign(campaignID, customerIDs)
+}
@@ -0,0 +1,47 @@
+// internal/service/campaign_clone.go
+package service
+
+import (
+	"github.com/unclebandit/smsleopard-backend/internal/campaignstate"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+)
+
+// CloneSuffix is appended to the name of a cloned campaign unless the request names it
+const CloneSuffix = " (copy)"
+
+// CloneCampaign copies a campaign's channel, templates, variables, sending policies and audience
+// into a new draft, applies overrides the same way UpdateCampaign applies a patch and records
+// the source campaign for reporting. Campaigns in any status can be cloned; deleted ones cannot.
+func (s *CampaignService) CloneCampaign(id int, overrides CampaignPatch) (*model.Campaign, error) {
+	src, err := s.CampaignRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &model.Campaign{
+		Name:               src.Name + CloneSuffix,
+		Channel:            src.Channel,
+		Status:             campaignstate.Draft,
+		BaseTemplate:       src.BaseTemplate,
+		TemplateVariants:   src.TemplateVariants,
+		FallbackLanguage:   src.FallbackLanguage,
+		Variants:           src.Variants,
+		Variables:          src.Variables,
+		WhatsAppTemplateID: src.WhatsAppTemplateID,
+		WhatsAppParameters: src.WhatsAppParameters,
+		FrequencyCapPolicy: src.FrequencyCapPolicy,
+		QuietHours:         src.QuietHours,
+		Audience:           src.Audience,
+		SourceCampaignID:   &src.ID,
+	}
+	if err := s.applyPatch(c, overrides); err != nil {
+		return nil, err
+	}
+	if err := s.validateCampaign(c); err != nil {
+		return nil, err
+	}
+	if err := s.CampaignRepo.Create(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,78 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+// MockCloneRepo records the campaigns created from the one MockEditRepo stores
+type MockCloneRepo struct {
+	*MockEditRepo
+	created []model.Campaign
+}
+
+func (m *MockCloneRepo) Create(c *model.Campaign) error {
+	c.ID = 100 + len(m.created)
+	m.created = append(m.created, *c)
+	return nil
+}
+
+func TestCloneCampaign(t *testing.T) {
+	segment := 7
+	edit := newMockEditRepo("sent")
+	edit.campaign.Variables = map[string]string{"code": "SAVE10"}
+	edit.campaign.Audience = &model.CampaignAudience{SegmentID: &segment}
+	repo := &MockCloneRepo{MockEditRepo: edit}
+	svc := &service.CampaignService{CampaignRepo: repo}
+
+	c, err := svc.CloneCampaign(1, service.CampaignPatch{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.ID != 100 || c.Status != "draft" || c.Name != "Sale (copy)" || c.Channel != "sms" || c.BaseTemplate != "Hi {first_name}" {
+		t.Errorf("unexpected clone %+v", c)
+	}
+	if c.Variables["code"] != "SAVE10" || c.Audience == nil || c.Audience.SegmentID == nil || *c.Audience.SegmentID != 7 {
+		t.Errorf("expected variables and audience copied, got %+v", c)
+	}
+	if c.SourceCampaignID == nil || *c.SourceCampaignID != 1 {
+		t.Errorf("expected source campaign 1, got %v", c.SourceCampaignID)
+	}
+
+	// overrides replace what is copied, including the audience
+	name, channel := "Sale again", "whatsapp"
+	tags := &model.CampaignAudience{TagExpression: "vip AND NOT churn-risk"}
+	c, err = svc.CloneCampaign(1, service.CampaignPatch{Name: &name, Channel: &channel, Audience: tags})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Name != "Sale again" || c.Channel != "whatsapp" || c.Audience.SegmentID != nil || c.Audience.TagExpression != tags.TagExpression {
+		t.Errorf("expected overrides applied, got %+v", c)
+	}
+	if c, err = svc.CloneCampaign(1, service.CampaignPatch{Audience: &model.CampaignAudience{}}); err != nil || c.Audience != nil {
+		t.Errorf("expected an empty audience to clear it, got %+v (%v)", c, err)
+	}
+
+	var validation *appErrors.ErrValidation
+	both := &model.CampaignAudience{SegmentID: &segment, TagExpression: "vip"}
+	if _, err := svc.CloneCampaign(1, service.CampaignPatch{Audience: both}); !errors.As(err, &validation) || validation.Field != "audience" {
+		t.Errorf("expected two targets to be rejected, got %v", err)
+	}
+	bad := &model.CampaignAudience{TagExpression: "vip AND"}
+	if _, err := svc.CloneCampaign(1, service.CampaignPatch{Audience: bad}); !errors.As(err, &validation) || validation.Field != "tag_expression" {
+		t.Errorf("expected bad tag expression to be rejected, got %v", err)
+	}
+
+	var notFound *appErrors.ErrCampaignNotFound
+	edit.deleted = true
+	if _, err := svc.CloneCampaign(1, service.CampaignPatch{}); !errors.As(err, &notFound) {
+		t.Errorf("expected deleted campaign not to be cloned, got %v", err)
+	}
+	if len(repo.created) != 3 {
+		t.Errorf("expected 3 clones created, got %d", len(repo.created))
+	}
+}
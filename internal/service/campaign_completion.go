@@ -0,0 +1,125 @@
+// internal/service/campaign_completion.go
+package service
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unclebandit/smsleopard-backend/internal/campaignstate"
+	"github.com/unclebandit/smsleopard-backend/internal/queue"
+	"github.com/unclebandit/smsleopard-backend/internal/repository"
+)
+
+// MaxSendRetries is how often a message whose delivery failed is tried again before it is
+// marked failed for good. It matches the queue's own retry limit.
+const MaxSendRetries = 3
+
+// DefaultFailureThreshold is the share of failed messages above which a campaign fails
+const DefaultFailureThreshold = 0.5
+
+// unfinishedStatuses are the message statuses still waiting for an outcome
+var unfinishedStatuses = repository.UnfinishedMessageStatuses
+
+// FailureStatus is the status a message moves to when a delivery attempt fails, given how
+// many times it was retried already: 'retrying' while retries are left, then 'failed'
+func FailureStatus(retryCount int) string {
+	if retryCount < MaxSendRetries {
+		return "retrying"
+	}
+	return "failed"
+}
+
+// CampaignCompleted is published on the campaign_completed topic when a campaign finishes
+type CampaignCompleted struct {
+	CampaignID  int       `json:"campaign_id"`
+	Status      string    `json:"status"`
+	Total       int       `json:"total"`
+	Sent        int       `json:"sent"`
+	Failed      int       `json:"failed"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// Completion finalizes sending campaigns once none of their messages await an outcome
+type Completion struct {
+	// FailureThreshold is the share of attempted (sent or failed) messages, from 0 to 1,
+	// that may fail before the campaign as a whole is marked failed
+	FailureThreshold float64
+
+	// Events receives a CampaignCompleted for every finished campaign; nil publishes nothing
+	Events queue.Queue
+}
+
+// ParseFailureThreshold reads the failure threshold from configuration, either a fraction
+// such as "0.2" or a percentage such as "20%". Empty uses DefaultFailureThreshold.
+func ParseFailureThreshold(threshold string) (*Completion, error) {
+	c := &Completion{FailureThreshold: DefaultFailureThreshold}
+	if threshold == "" {
+		return c, nil
+	}
+	value, percent := strings.CutSuffix(strings.TrimSpace(threshold), "%")
+	f, err := strconv.ParseFloat(value, 64)
+	if percent {
+		f /= 100
+	}
+	if err != nil || f < 0 || f > 1 {
+		return nil, fmt.Errorf("failure threshold must be between 0 and 1 (or 0%% and 100%%), got %q", threshold)
+	}
+	c.FailureThreshold = f
+	return c, nil
+}
+
+// Check finalizes campaignID when it is sending and has no unfinished messages left: it
+// becomes 'failed' when more than FailureThreshold of the attempted messages failed and
+// 'sent' otherwise. It returns the completion, or nil when the campaign is not done yet or
+// another worker finalized it first.
+func (c *Completion) Check(repo repository.CampaignRepositoryInterface, campaignID int) (*CampaignCompleted, error) {
+	if c == nil {
+		return nil, nil
+	}
+	campaign, err := repo.GetByID(campaignID)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.Status != campaignstate.Sending {
+		return nil, nil
+	}
+
+	stats, err := repo.GetCampaignStats(campaignID)
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range unfinishedStatuses {
+		if stats[status] > 0 {
+			return nil, nil
+		}
+	}
+
+	event := &CampaignCompleted{CampaignID: campaignID, Status: campaignstate.Sent, Sent: stats["sent"], Failed: stats["failed"]}
+	for _, n := range stats {
+		event.Total += n
+	}
+	// suppressed, skipped and cancelled messages were never attempted
+	if attempted := event.Sent + event.Failed; attempted > 0 && float64(event.Failed)/float64(attempted) > c.FailureThreshold {
+		event.Status = campaignstate.Failed
+	}
+	if err := campaignstate.Check(campaignID, campaign.Status, event.Status); err != nil {
+		return nil, err
+	}
+
+	event.CompletedAt = time.Now()
+	ok, err := repo.CompleteCampaign(campaignID, event.Status, event.CompletedAt)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	log.Printf("🏁 Campaign %d finished as %s (%d sent, %d failed of %d)\n", campaignID, event.Status, event.Sent, event.Failed, event.Total)
+	if c.Events != nil {
+		if err := c.Events.Publish("campaign_completed", *event); err != nil {
+			log.Println("⚠️ failed to publish completion of campaign", campaignID, ":", err)
+		}
+	}
+	return event, nil
+}
@@ -0,0 +1,146 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+func TestParseFailureThreshold(t *testing.T) {
+	for in, want := range map[string]float64{"": service.DefaultFailureThreshold, "0.2": 0.2, "20%": 0.2, "0": 0, "1": 1} {
+		c, err := service.ParseFailureThreshold(in)
+		if err != nil || c.FailureThreshold != want {
+			t.Errorf("ParseFailureThreshold(%q) = %v, %v; want %v", in, c, err, want)
+		}
+	}
+	for _, in := range []string{"1.5", "-0.1", "150%", "half"} {
+		if _, err := service.ParseFailureThreshold(in); err == nil {
+			t.Errorf("ParseFailureThreshold(%q): expected an error", in)
+		}
+	}
+}
+
+func TestCampaignCompletesWhenAllMessagesFinish(t *testing.T) {
+	repo := newMockLifecycleRepo("draft")
+	events := &MockQueue{}
+	completion := &service.Completion{FailureThreshold: 0.5, Events: events}
+	svc := &service.CampaignService{CampaignRepo: repo, CustomerRepo: &MockCustomerRepo{}, Queue: &MockQueue{}, Completion: completion}
+	dispatch := &service.DispatchService{CampaignRepo: repo, Completion: completion, Send: func(*model.OutboundMessage) error { return nil }}
+
+	result, err := svc.SendCampaign(1, []int{1, 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dispatch.Deliver(result.MessageIDs[0])
+	if repo.status != "sending" || len(events.published) != 0 {
+		t.Fatalf("expected campaign still sending with a message pending, got %s", repo.status)
+	}
+
+	dispatch.Deliver(result.MessageIDs[1])
+	if repo.status != "sent" || repo.completedAt == nil {
+		t.Fatalf("expected campaign sent with completed_at, got %s %v", repo.status, repo.completedAt)
+	}
+	if len(events.published) != 1 {
+		t.Fatalf("expected one completion event, got %d", len(events.published))
+	}
+	event := events.published[0].(service.CampaignCompleted)
+	if event.CampaignID != 1 || event.Status != "sent" || event.Sent != 2 || event.Total != 2 {
+		t.Errorf("unexpected completion event %+v", event)
+	}
+
+	// a redelivered job does not complete the campaign twice
+	dispatch.Deliver(result.MessageIDs[1])
+	if len(events.published) != 1 {
+		t.Errorf("expected no second completion event, got %d", len(events.published))
+	}
+}
+
+func TestCampaignFailsAboveThreshold(t *testing.T) {
+	repo := newMockLifecycleRepo("draft")
+	completion := &service.Completion{FailureThreshold: 0.4}
+	svc := &service.CampaignService{CampaignRepo: repo, CustomerRepo: &MockCustomerRepo{}, Queue: &MockQueue{}, Completion: completion}
+	dispatch := &service.DispatchService{CampaignRepo: repo, Completion: completion, Send: func(msg *model.OutboundMessage) error {
+		if msg.ID == 1 {
+			return nil
+		}
+		return errors.New("provider unavailable")
+	}}
+
+	result, _ := svc.SendCampaign(1, []int{1, 2})
+	dispatch.Deliver(result.MessageIDs[0])
+
+	// every attempt but the last asks the queue to retry
+	failing := result.MessageIDs[1]
+	for i := 0; i < service.MaxSendRetries; i++ {
+		if err := dispatch.Deliver(failing); err == nil {
+			t.Fatalf("attempt %d: expected an error to trigger a retry", i+1)
+		}
+		if repo.statuses[failing] != "retrying" || repo.status != "sending" {
+			t.Fatalf("attempt %d: expected message retrying and campaign sending, got %s / %s", i+1, repo.statuses[failing], repo.status)
+		}
+	}
+	if err := dispatch.Deliver(failing); err != nil {
+		t.Fatalf("expected the final attempt not to be retried, got %v", err)
+	}
+	if repo.statuses[failing] != "failed" {
+		t.Errorf("expected message failed after %d retries, got %s", service.MaxSendRetries, repo.statuses[failing])
+	}
+	// 1 of 2 attempted messages failed, above the 40% threshold
+	if repo.status != "failed" || repo.completedAt == nil {
+		t.Errorf("expected campaign failed, got %s", repo.status)
+	}
+}
+
+func TestHeldMessagesKeepTheirRetries(t *testing.T) {
+	repo := newMockLifecycleRepo("draft")
+	svc := &service.CampaignService{CampaignRepo: repo, CustomerRepo: &MockCustomerRepo{}, Queue: &MockQueue{}}
+	dispatch := &service.DispatchService{CampaignRepo: repo, Send: func(*model.OutboundMessage) error {
+		return errors.New("provider unavailable")
+	}}
+
+	result, _ := svc.SendCampaign(1, []int{1})
+	id := result.MessageIDs[0]
+
+	// being held back by a pause is not a delivery attempt
+	svc.PauseCampaign(1)
+	dispatch.Deliver(id)
+	svc.ResumeCampaign(1)
+	if repo.retries[id] != 0 {
+		t.Fatalf("expected no retries used while paused, got %d", repo.retries[id])
+	}
+
+	for i := 0; i < service.MaxSendRetries; i++ {
+		if err := dispatch.Deliver(id); err == nil || repo.statuses[id] != "retrying" {
+			t.Fatalf("attempt %d: expected a retry, got %s (%v)", i+1, repo.statuses[id], err)
+		}
+	}
+	if err := dispatch.Deliver(id); err != nil || repo.statuses[id] != "failed" {
+		t.Errorf("expected message failed after %d retries, got %s (%v)", service.MaxSendRetries, repo.statuses[id], err)
+	}
+}
+
+func TestCampaignWithNothingToQueueCompletes(t *testing.T) {
+	suppressions := &MockSuppressionRepo{}
+	suppressions.Create(&model.Suppression{CustomerID: 1, Channel: "all"})
+	suppressions.Create(&model.Suppression{CustomerID: 2, Channel: "sms"})
+
+	repo := newMockLifecycleRepo("draft")
+	svc := &service.CampaignService{
+		CampaignRepo:    repo,
+		CustomerRepo:    &MockCustomerRepo{},
+		SuppressionRepo: suppressions,
+		Queue:           &MockQueue{},
+		Completion:      &service.Completion{FailureThreshold: 0.5},
+	}
+
+	result, err := svc.SendCampaign(1, []int{1, 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Suppressed != 2 || repo.status != "sent" {
+		t.Errorf("expected fully suppressed campaign to complete as sent, got %+v / %s", result, repo.status)
+	}
+}
@@ -0,0 +1,91 @@
+// internal/service/campaign_lifecycle.go
+package service
+
+import (
+	"log"
+
+	"github.com/unclebandit/smsleopard-backend/internal/campaignstate"
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+)
+
+// StatusChange is the outcome of pausing, resuming or cancelling a campaign. Messages is
+// how many outbound messages were held, released or dropped as a result.
+type StatusChange struct {
+	CampaignID int
+	From       string
+	To         string
+	Messages   int
+}
+
+// transition moves campaign to status to, rejecting illegal moves and moves raced by
+// another request with ErrInvalidTransition
+func (s *CampaignService) transition(campaign *model.Campaign, to string) error {
+	if err := campaignstate.Check(campaign.ID, campaign.Status, to); err != nil {
+		return err
+	}
+	ok, err := s.CampaignRepo.TransitionStatus(campaign.ID, campaign.Status, to)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// the status changed since the campaign was loaded
+		return appErrors.NewInvalidTransition(campaign.ID, campaign.Status, to)
+	}
+	campaign.Status = to
+	return nil
+}
+
+// changeStatus loads a campaign and moves it to status to
+func (s *CampaignService) changeStatus(campaignID int, to string) (*StatusChange, error) {
+	campaign, err := s.CampaignRepo.GetByID(campaignID)
+	if err != nil {
+		return nil, err
+	}
+	change := &StatusChange{CampaignID: campaignID, From: campaign.Status, To: to}
+	if err := s.transition(campaign, to); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// PauseCampaign stops delivery of a sending campaign. Messages already queued are held as
+// 'paused' when the dispatcher picks them up.
+func (s *CampaignService) PauseCampaign(campaignID int) (*StatusChange, error) {
+	return s.changeStatus(campaignID, campaignstate.Paused)
+}
+
+// ResumeCampaign restarts delivery of a paused campaign and queues its held messages again
+func (s *CampaignService) ResumeCampaign(campaignID int) (*StatusChange, error) {
+	change, err := s.changeStatus(campaignID, campaignstate.Sending)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := s.CampaignRepo.UpdateCampaignMessageStatuses(campaignID, []string{"paused"}, "pending", "")
+	if err != nil {
+		return change, err
+	}
+	for _, id := range ids {
+		if err := s.Queue.Publish("campaign_sends", id); err != nil {
+			log.Println("⚠️ failed to enqueue message ID", id, ":", err)
+			continue
+		}
+		change.Messages++
+	}
+	return change, nil
+}
+
+// CancelCampaign stops a campaign for good. Messages not yet sent are marked 'cancelled';
+// any still in the queue are dropped by the dispatcher.
+func (s *CampaignService) CancelCampaign(campaignID int) (*StatusChange, error) {
+	change, err := s.changeStatus(campaignID, campaignstate.Cancelled)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := s.CampaignRepo.UpdateCampaignMessageStatuses(campaignID, unfinishedStatuses, "cancelled", "campaign cancelled")
+	if err != nil {
+		return change, err
+	}
+	change.Messages = len(ids)
+	return change, nil
+}
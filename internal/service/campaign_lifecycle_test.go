@@ -0,0 +1,176 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+// MockLifecycleRepo keeps one campaign's status and its messages' statuses and retries in memory
+type MockLifecycleRepo struct {
+	MockDispatchRepo
+	status      string
+	completedAt *time.Time
+	retries     map[int]int
+}
+
+func newMockLifecycleRepo(status string) *MockLifecycleRepo {
+	return &MockLifecycleRepo{MockDispatchRepo: MockDispatchRepo{statuses: map[int]string{}}, status: status, retries: map[int]int{}}
+}
+
+func (m *MockLifecycleRepo) GetOutboundMessageByID(id int) (*model.OutboundMessage, error) {
+	msg, err := m.MockDispatchRepo.GetOutboundMessageByID(id)
+	msg.RetryCount = m.retries[id]
+	return msg, err
+}
+
+func (m *MockLifecycleRepo) UpdateOutboundMessageStatus(id int, status, lastError string) error {
+	if status == "failed" || status == "retrying" {
+		m.retries[id]++
+	}
+	return m.MockDispatchRepo.UpdateOutboundMessageStatus(id, status, lastError)
+}
+
+func (m *MockLifecycleRepo) GetCampaignStats(campaignID int) (map[string]int, error) {
+	stats := map[string]int{}
+	for _, status := range m.statuses {
+		stats[status]++
+	}
+	return stats, nil
+}
+
+func (m *MockLifecycleRepo) CompleteCampaign(id int, status string, completedAt time.Time) (bool, error) {
+	if m.status != "sending" {
+		return false, nil
+	}
+	m.status, m.completedAt = status, &completedAt
+	return true, nil
+}
+
+func (m *MockLifecycleRepo) GetByID(id int) (*model.Campaign, error) {
+	c, _ := m.MockDispatchRepo.GetByID(id)
+	c.Status = m.status
+	return c, nil
+}
+
+func (m *MockLifecycleRepo) TransitionStatus(id int, from, to string) (bool, error) {
+	if m.status != from {
+		return false, nil
+	}
+	m.status = to
+	return true, nil
+}
+
+func (m *MockLifecycleRepo) CreateOutboundMessage(campaignID, customerID int) (*model.OutboundMessage, error) {
+	msg, err := m.MockDispatchRepo.CreateOutboundMessage(campaignID, customerID)
+	m.statuses[msg.ID] = msg.Status
+	return msg, err
+}
+
+func (m *MockLifecycleRepo) UpdateCampaignMessageStatuses(campaignID int, from []string, to, reason string) ([]int, error) {
+	ids := []int{}
+	for id, status := range m.statuses {
+		for _, f := range from {
+			if status == f {
+				m.statuses[id] = to
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids, nil
+}
+
+func TestSendCampaignRejectsSendingCampaign(t *testing.T) {
+	repo := newMockLifecycleRepo("draft")
+	svc := &service.CampaignService{CampaignRepo: repo, CustomerRepo: &MockCustomerRepo{}, Queue: &MockQueue{}}
+
+	if _, err := svc.SendCampaign(1, []int{1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.status != "sending" {
+		t.Errorf("expected campaign to be sending, got %s", repo.status)
+	}
+
+	var invalid *appErrors.ErrInvalidTransition
+	if _, err := svc.SendCampaign(1, []int{2}); !errors.As(err, &invalid) || invalid.From != "sending" {
+		t.Errorf("expected a second send to be rejected, got %v", err)
+	}
+}
+
+func TestPauseResumeCampaign(t *testing.T) {
+	repo := newMockLifecycleRepo("draft")
+	q := &MockQueue{}
+	svc := &service.CampaignService{CampaignRepo: repo, CustomerRepo: &MockCustomerRepo{}, Queue: q}
+	dispatch := &service.DispatchService{CampaignRepo: repo, Send: func(*model.OutboundMessage) error { return nil }}
+
+	var invalid *appErrors.ErrInvalidTransition
+	if _, err := svc.PauseCampaign(1); !errors.As(err, &invalid) {
+		t.Fatalf("expected a draft campaign not to pause, got %v", err)
+	}
+
+	result, _ := svc.SendCampaign(1, []int{1, 2})
+	if _, err := svc.PauseCampaign(1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// queued messages picked up while paused are held rather than sent
+	for _, id := range result.MessageIDs {
+		if err := dispatch.Deliver(id); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if repo.statuses[id] != "paused" {
+			t.Errorf("expected message %d paused, got %s", id, repo.statuses[id])
+		}
+	}
+
+	q.published = nil
+	change, err := svc.ResumeCampaign(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if change.From != "paused" || change.To != "sending" || change.Messages != 2 || len(q.published) != 2 {
+		t.Errorf("expected 2 paused messages re-queued, got %+v (published %d)", change, len(q.published))
+	}
+	for _, id := range result.MessageIDs {
+		dispatch.Deliver(id)
+		if repo.statuses[id] != "sent" {
+			t.Errorf("expected message %d sent after resume, got %s", id, repo.statuses[id])
+		}
+	}
+}
+
+func TestCancelCampaign(t *testing.T) {
+	repo := newMockLifecycleRepo("draft")
+	svc := &service.CampaignService{CampaignRepo: repo, CustomerRepo: &MockCustomerRepo{}, Queue: &MockQueue{}}
+	dispatch := &service.DispatchService{CampaignRepo: repo, Send: func(*model.OutboundMessage) error {
+		t.Error("cancelled campaigns must not send")
+		return nil
+	}}
+
+	result, _ := svc.SendCampaign(1, []int{1, 2})
+	repo.statuses[result.MessageIDs[0]] = "sent"
+
+	change, err := svc.CancelCampaign(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if change.Messages != 1 || repo.statuses[result.MessageIDs[0]] != "sent" || repo.statuses[result.MessageIDs[1]] != "cancelled" {
+		t.Errorf("expected only the unsent message cancelled, got %+v %v", change, repo.statuses)
+	}
+
+	// a message awaiting a retry when the campaign was cancelled is dropped too
+	repo.statuses[result.MessageIDs[1]] = "retrying"
+	dispatch.Deliver(result.MessageIDs[1])
+	if repo.statuses[result.MessageIDs[1]] != "cancelled" {
+		t.Errorf("expected redelivered message dropped, got %s", repo.statuses[result.MessageIDs[1]])
+	}
+
+	var invalid *appErrors.ErrInvalidTransition
+	if _, err := svc.ResumeCampaign(1); !errors.As(err, &invalid) {
+		t.Errorf("expected a cancelled campaign not to resume, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+// internal/service/campaign_scheduler.go
+package service
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/unclebandit/smsleopard-backend/internal/campaignstate"
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/repository"
+)
+
+// DefaultSchedulerBatch is how many campaigns one scheduler pass dispatches at most
+const DefaultSchedulerBatch = 20
+
+// CampaignScheduler sends scheduled campaigns to their stored audience once they are due.
+// Every replica may run it: a pass only proceeds while holding a Postgres advisory lock, and
+// claiming a campaign is conditional on it still being scheduled, so each campaign is dispatched
+// once. A pass that dies part-way leaves the campaign sending without dispatched_at, and the next
+// pass finishes it; messages are created idempotently, so customers already messaged are not
+// messaged again.
+type CampaignScheduler struct {
+	Repo      repository.CampaignScheduleRepositoryInterface
+	Campaigns *CampaignService
+	BatchSize int // 0 uses DefaultSchedulerBatch
+}
+
+// Tick runs one scheduler pass and returns how many campaigns it dispatched. It does nothing
+// while another replica runs a pass. A campaign that fails to dispatch is logged and retried on
+// the next pass.
+func (s *CampaignScheduler) Tick(now time.Time) (int, error) {
+	limit := s.BatchSize
+	if limit <= 0 {
+		limit = DefaultSchedulerBatch
+	}
+
+	dispatched := 0
+	_, err := s.Repo.WithSchedulerLock(func() error {
+		ids, err := s.Repo.DueCampaigns(now, limit)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			ok, err := s.dispatch(id, now)
+			if err != nil {
+				log.Println("⚠️ failed to dispatch scheduled campaign", id, ":", err)
+				continue
+			}
+			if ok {
+				dispatched++
+			}
+		}
+		return nil
+	})
+	return dispatched, err
+}
+
+// dispatch claims a due campaign, or picks up one whose dispatch was interrupted, and sends it to
+// its audience. It reports false when the campaign changed since it was listed.
+func (s *CampaignScheduler) dispatch(campaignID int, now time.Time) (bool, error) {
+	campaign, err := s.Campaigns.CampaignRepo.GetByID(campaignID)
+	if err != nil {
+		return false, err
+	}
+
+	switch campaign.Status {
+	case campaignstate.Scheduled:
+		if err := campaignstate.Check(campaign.ID, campaign.Status, campaignstate.Sending); err != nil {
+			return false, err
+		}
+		claimed, err := s.Repo.ClaimScheduled(campaignID, now)
+		if err != nil || !claimed {
+			return false, err // sent by hand, unscheduled or deleted in the meantime
+		}
+		campaign.Status = campaignstate.Sending
+	case campaignstate.Sending:
+		log.Println("🔁 Resuming interrupted dispatch of campaign", campaignID)
+	default:
+		return false, nil
+	}
+
+	customerIDs, err := s.Campaigns.audienceCustomerIDs(campaign.Audience)
+	if err != nil {
+		if !permanent(err) {
+			return false, err
+		}
+		// retrying cannot help, e.g. the segment was deleted
+		log.Println("⚠️ Campaign", campaignID, "cannot reach its audience, failing it:", err)
+		if _, err := s.Campaigns.CampaignRepo.CompleteCampaign(campaignID, campaignstate.Failed, now); err != nil {
+			return false, err
+		}
+		return false, s.Repo.MarkDispatched(campaignID, now)
+	}
+
+	result, err := s.Campaigns.send(campaign, customerIDs)
+	if err != nil {
+		return false, err
+	}
+	if err := s.Repo.MarkDispatched(campaignID, now); err != nil {
+		return false, err
+	}
+	log.Println("📅 Dispatched scheduled campaign", campaignID, ":", result.MessagesQueued, "messages queued")
+	return true, nil
+}
+
+// permanent reports whether err comes from the audience itself rather than from reaching the database
+func permanent(err error) bool {
+	var validation *appErrors.ErrValidation
+	var segment *appErrors.ErrSegmentNotFound
+	return errors.As(err, &validation) || errors.As(err, &segment)
+}
@@ -0,0 +1,196 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+// MockScheduledRepo is a lifecycle repo whose campaign has an audience and whose messages are
+// created once per customer, like the real repository
+type MockScheduledRepo struct {
+	*MockLifecycleRepo
+	audience *model.CampaignAudience
+	messages map[int]*model.OutboundMessage // by customer ID
+}
+
+func (m *MockScheduledRepo) GetByID(id int) (*model.Campaign, error) {
+	c, err := m.MockLifecycleRepo.GetByID(id)
+	c.Audience = m.audience
+	return c, err
+}
+
+func (m *MockScheduledRepo) CreateOutboundMessage(campaignID, customerID int) (*model.OutboundMessage, error) {
+	if msg, ok := m.messages[customerID]; ok {
+		return msg, nil
+	}
+	msg, err := m.MockLifecycleRepo.CreateOutboundMessage(campaignID, customerID)
+	m.messages[customerID] = msg
+	return msg, err
+}
+
+// MockScheduleRepo tracks the scheduler lock and the dispatch stamps of the campaign in MockScheduledRepo
+type MockScheduleRepo struct {
+	campaigns  *MockScheduledRepo
+	locked     bool
+	started    bool
+	dispatched bool
+	now        time.Time
+	scheduled  time.Time
+}
+
+func (m *MockScheduleRepo) WithSchedulerLock(fn func() error) (bool, error) {
+	if m.locked {
+		return false, nil
+	}
+	m.locked = true
+	defer func() { m.locked = false }()
+	return true, fn()
+}
+
+func (m *MockScheduleRepo) DueCampaigns(now time.Time, limit int) ([]int, error) {
+	status := m.campaigns.status
+	if (status == "scheduled" && !m.scheduled.After(now)) || (status == "sending" && m.started && !m.dispatched) {
+		return []int{1}, nil
+	}
+	return []int{}, nil
+}
+
+func (m *MockScheduleRepo) ClaimScheduled(campaignID int, now time.Time) (bool, error) {
+	if m.campaigns.status != "scheduled" {
+		return false, nil
+	}
+	m.campaigns.status, m.started = "sending", true
+	return true, nil
+}
+
+func (m *MockScheduleRepo) MarkDispatched(campaignID int, now time.Time) error {
+	m.dispatched = true
+	return nil
+}
+
+func newScheduler(status string, customerIDs ...int) (*service.CampaignScheduler, *MockScheduleRepo, *MockQueue) {
+	campaigns := &MockScheduledRepo{
+		MockLifecycleRepo: newMockLifecycleRepo(status),
+		audience:          &model.CampaignAudience{CustomerIDs: customerIDs},
+		messages:          map[int]*model.OutboundMessage{},
+	}
+	q := &MockQueue{}
+	repo := &MockScheduleRepo{campaigns: campaigns, scheduled: time.Now().Add(-time.Minute)}
+	return &service.CampaignScheduler{
+		Repo:      repo,
+		Campaigns: &service.CampaignService{CampaignRepo: campaigns, CustomerRepo: &MockCustomerRepo{}, Queue: q},
+	}, repo, q
+}
+
+func TestSchedulerDispatchesDueCampaigns(t *testing.T) {
+	scheduler, repo, q := newScheduler("scheduled", 1, 2)
+
+	// another replica is running a pass
+	repo.locked = true
+	if n, err := scheduler.Tick(time.Now()); err != nil || n != 0 || repo.campaigns.status != "scheduled" {
+		t.Fatalf("expected nothing dispatched without the lock, got %d (%v)", n, err)
+	}
+	repo.locked = false
+
+	if n, err := scheduler.Tick(time.Now().Add(-time.Hour)); err != nil || n != 0 {
+		t.Fatalf("expected nothing dispatched before scheduled_at, got %d (%v)", n, err)
+	}
+
+	n, err := scheduler.Tick(time.Now())
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 campaign dispatched, got %d (%v)", n, err)
+	}
+	if repo.campaigns.status != "sending" || !repo.dispatched || len(repo.campaigns.messages) != 2 || len(q.published) != 2 {
+		t.Errorf("expected campaign sending with 2 messages queued, got %s, %d messages, %d queued",
+			repo.campaigns.status, len(repo.campaigns.messages), len(q.published))
+	}
+
+	// a later pass finds nothing to do
+	if n, err := scheduler.Tick(time.Now()); err != nil || n != 0 || len(q.published) != 2 {
+		t.Errorf("expected no second dispatch, got %d (%v)", n, err)
+	}
+}
+
+func TestSchedulerResumesInterruptedDispatch(t *testing.T) {
+	scheduler, repo, _ := newScheduler("sending", 1, 2)
+
+	// a pass claimed the campaign and messaged customer 1 before the process died
+	repo.started = true
+	first, _ := repo.campaigns.CreateOutboundMessage(1, 1)
+	repo.campaigns.UpdateOutboundMessageStatus(first.ID, "sent", "")
+
+	if n, err := scheduler.Tick(time.Now()); err != nil || n != 1 || !repo.dispatched {
+		t.Fatalf("expected the interrupted dispatch to finish, got %d (%v)", n, err)
+	}
+	if len(repo.campaigns.messages) != 2 || repo.campaigns.statuses[first.ID] != "sent" {
+		t.Errorf("expected customer 1 to keep their sent message, got %d messages, status %q",
+			len(repo.campaigns.messages), repo.campaigns.statuses[first.ID])
+	}
+}
+
+func TestSchedulerSkipsCampaignsSentByHand(t *testing.T) {
+	scheduler, repo, q := newScheduler("sending", 1)
+
+	// sent through the API, so no scheduler pass ever claimed it
+	if n, err := scheduler.Tick(time.Now()); err != nil || n != 0 || len(q.published) != 0 || repo.dispatched {
+		t.Errorf("expected a campaign sent by hand to be left alone, got %d (%v)", n, err)
+	}
+}
+
+// MockRacingRepo returns messages as they were read before another worker claimed them
+type MockRacingRepo struct {
+	*MockScheduledRepo
+}
+
+func (m *MockRacingRepo) GetOutboundMessageByID(id int) (*model.OutboundMessage, error) {
+	msg, err := m.MockScheduledRepo.GetOutboundMessageByID(id)
+	msg.Status = "pending"
+	return msg, err
+}
+
+func TestResumedDispatchDeliversEachMessageOnce(t *testing.T) {
+	scheduler, repo, q := newScheduler("sending", 1, 2)
+
+	// the first pass created and queued customer 1's message before the process died
+	repo.started = true
+	first, _ := repo.campaigns.CreateOutboundMessage(1, 1)
+
+	if n, err := scheduler.Tick(time.Now()); err != nil || n != 1 || len(q.published) != 2 {
+		t.Fatalf("expected the resumed dispatch to queue both messages, got %d dispatched, %d queued (%v)", n, len(q.published), err)
+	}
+
+	sent := map[int]int{}
+	dispatch := &service.DispatchService{
+		CampaignRepo: &MockRacingRepo{repo.campaigns},
+		Send: func(msg *model.OutboundMessage) error {
+			sent[msg.ID]++
+			return nil
+		},
+	}
+
+	// a worker handling the job queued by the first pass claims customer 1's message, so a
+	// second worker handling the requeued job must not send it again
+	if claimed, _ := repo.campaigns.ClaimOutboundMessage(first.ID); !claimed {
+		t.Fatal("expected the first worker to claim the message")
+	}
+	for _, payload := range q.published {
+		if err := dispatch.Deliver(payload.(int)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if sent[first.ID] != 0 || repo.campaigns.statuses[first.ID] != "sending" {
+		t.Errorf("expected the claimed message to be left to its worker, sent %d times, status %q",
+			sent[first.ID], repo.campaigns.statuses[first.ID])
+	}
+	second := repo.campaigns.messages[2]
+	if sent[second.ID] != 1 || repo.campaigns.statuses[second.ID] != "sent" {
+		t.Errorf("expected customer 2's message sent once, sent %d times, status %q",
+			sent[second.ID], repo.campaigns.statuses[second.ID])
+	}
+	if repo.campaigns.status != "sending" {
+		t.Errorf("expected the campaign to wait for the message in flight, got %s", repo.campaigns.status)
+	}
+}
@@ -4,12 +4,18 @@ package service
 import (
     "fmt"
     "log"
+    "math"
+    "sort"
     "strings"
     "time"
 
+    "github.com/unclebandit/smsleopard-backend/internal/campaignstate"
+    appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
     "github.com/unclebandit/smsleopard-backend/internal/model"
     "github.com/unclebandit/smsleopard-backend/internal/repository"
     "github.com/unclebandit/smsleopard-backend/internal/queue"
+    "github.com/unclebandit/smsleopard-backend/internal/sms"
+    "github.com/unclebandit/smsleopard-backend/internal/template"
 )
 
 type CampaignService struct {
@@ -17,74 +23,194 @@ type CampaignService struct {
     CustomerRepo repository.CustomerRepositoryInterface
     OutboundRepo *repository.OutboundMessageRepository
     Queue        queue.Queue
+
+    WhatsAppTemplateRepo repository.WhatsAppTemplateRepositoryInterface
+
+    // LinkRepo stores the short links {link:...} targets are replaced with; LinkBaseURL
+    // is the public address of this service they are served under
+    LinkRepo    repository.ShortLinkRepositoryInterface
+    LinkBaseURL string
+
+    // SegmentRepo resolves saved audience segments into send targets
+    SegmentRepo repository.SegmentRepositoryInterface
+
+    // SuppressionRepo lists customers who opted out; nil disables the check
+    SuppressionRepo repository.SuppressionRepositoryInterface
+
+    // ConsentRepo holds marketing consent; when set, customers without active consent
+    // for the campaign's channel are not messaged
+    ConsentRepo repository.ConsentRepositoryInterface
+
+    // FrequencyCap limits messages per customer and channel across campaigns; nil disables it
+    FrequencyCap *FrequencyCap
+
+    // Completion finalizes campaigns in which no message was left to queue; nil disables it
+    Completion *Completion
 }
 
 // Result struct for SendCampaign
 type SendCampaignResult struct {
     CampaignID     int
     MessagesQueued int
+    Suppressed     int // customers on the suppression list, recorded but not queued
+    NoConsent      int // customers without consent for the channel, skipped entirely
+    Capped         int // customers over the frequency cap, deferred or skipped by campaign policy
     Status         string
     MessageIDs     []int
 }
 
+// CampaignDetails is a campaign plus its delivery stats
 type CampaignDetails struct {
-    ID           int               `json:"id"`
-    Name         string            `json:"name"`
-    Channel      string            `json:"channel"`
-    Status       string            `json:"status"`
-    BaseTemplate string            `json:"base_template"`
-    ScheduledAt  *time.Time        `json:"scheduled_at,omitempty"`
-    CreatedAt    time.Time         `json:"created_at"`
-    UpdatedAt    *time.Time         `json:"updated_at"`
-    Stats        map[string]int    `json:"stats"`
+    *model.Campaign
+    Stats            map[string]int            `json:"stats"`
+    ClickThroughRate float64                   `json:"click_through_rate"` // % of sent messages with a clicked link
+    VariantStats     map[string]map[string]int `json:"variant_stats,omitempty"`
 }
 
 
 
 
-func (s *CampaignService) RenderPreview(campaignID, customerID int, overrideTemplate *string) (string, error) {
+// PreviewResult is a rendered message plus the placeholders that had no value for the customer
+// and how the message would be billed as SMS
+type PreviewResult struct {
+    Message            string
+    Language           string // template variant used; empty for override templates
+    Variant            string // A/B variant the customer is assigned to
+    TemplateParameters []string
+    MissingFields      []string
+    SMS                sms.Info
+}
+
+func (s *CampaignService) RenderPreview(campaignID, customerID int, overrideTemplate *string) (*PreviewResult, error) {
 
     campaign, err := s.CampaignRepo.GetByID(campaignID)
     if err != nil {
-        return "", err
+        return nil, err
     }
     if campaign == nil {
-        return "", fmt.Errorf("campaign not found")
+        return nil, appErrors.NewCampaignNotFound(campaignID)
     }
 
     customer, err := s.CustomerRepo.GetByID(customerID)
     if err != nil {
-        return "", err
+        return nil, err
     }
     if customer == nil {
-        return "", fmt.Errorf("customer not found")
+        return nil, appErrors.NewCustomerNotFound(customerID)
+    }
+
+    data := s.messageData(campaign, customer)
+    hasOverride := overrideTemplate != nil && strings.TrimSpace(*overrideTemplate) != ""
+
+    if campaign.WhatsAppTemplateID != nil && !hasOverride {
+        return s.previewWhatsApp(campaign, data)
     }
 
-    template := campaign.BaseTemplate
+    choice := s.templateFor(campaign, customer)
+    if hasOverride {
+        choice = templateChoice{Source: *overrideTemplate}
+    }
+    if strings.TrimSpace(choice.Source) == "" {
+        return nil, fmt.Errorf("template cannot be empty")
+    }
 
-    if overrideTemplate != nil && strings.TrimSpace(*overrideTemplate) != "" {
-        template = *overrideTemplate
+    tmpl, err := template.Parse(choice.Source)
+    if err != nil {
+        field := "base_template"
+        if hasOverride {
+            field = "override_template"
+        }
+        return nil, appErrors.NewInvalidTemplate(field, err.(template.Errors))
     }
 
-    if strings.TrimSpace(template) == "" {
-        return "", fmt.Errorf("template cannot be empty")
+    message := tmpl.ExecuteWithLinks(data, s.linkerFor(0, nil))
+    return &PreviewResult{
+        Message:       message,
+        Language:      choice.Language,
+        Variant:       choice.Variant,
+        MissingFields: tmpl.Missing(data),
+        SMS:           sms.Analyze(message),
+    }, nil
+}
+
+// RenderMessage renders the campaign's template for a customer exactly as it will be sent.
+// Preview, SendCampaign and the workers all go through here so their output never diverges.
+func (s *CampaignService) RenderMessage(campaign *model.Campaign, customer *model.Customer) (string, error) {
+    msg := &model.OutboundMessage{}
+    if err := s.RenderOutbound(campaign, customer, msg); err != nil {
+        return "", err
     }
+    return msg.RenderedContent, nil
+}
 
-    message := template
-    message = strings.ReplaceAll(message, "{first_name}", customer.FirstName)
-    message = strings.ReplaceAll(message, "{last_name}", customer.LastName)
-    message = strings.ReplaceAll(message, "{location}", customer.Location)
-    message = strings.ReplaceAll(message, "{preferred_product}", customer.PreferredProduct)
+// RenderOutbound fills the content fields of an outbound message for a customer. Links
+// are tracked against msg.ID, so the message must already be stored.
+func (s *CampaignService) RenderOutbound(campaign *model.Campaign, customer *model.Customer, msg *model.OutboundMessage) error {
+    data := s.messageData(campaign, customer)
+    var linkErr error
+    link := s.linkerFor(msg.ID, &linkErr)
+
+    if campaign.WhatsAppTemplateID != nil {
+        content, params, err := s.renderWhatsApp(campaign, data, link)
+        if err == nil {
+            err = linkErr
+        }
+        if err != nil {
+            return err
+        }
+        msg.RenderedContent = content
+        msg.TemplateParameters = params
+        return nil
+    }
 
-    return message, nil
+    choice := s.templateFor(campaign, customer)
+    if strings.TrimSpace(choice.Source) == "" {
+        return fmt.Errorf("template cannot be empty")
+    }
+    content, err := template.RenderWithLinks(choice.Source, data, link)
+    if err == nil {
+        err = linkErr
+    }
+    if err != nil {
+        return err
+    }
+    msg.RenderedContent = content
+    msg.Variant = choice.Variant
+    if campaign.Channel == "sms" {
+        msg.Segments = sms.Analyze(content).Segments
+    }
+    return nil
 }
 
+// DefaultLanguage labels the base template when no language variant is used
+const DefaultLanguage = "default"
+
+// templateChoice is the template source selected for one customer
+type templateChoice struct {
+    Source   string
+    Language string
+    Variant  string // A/B variant label, empty when the campaign has none
+}
 
-func replace(template, placeholder, value string) string {
-    if value == "" {
-        value = "<unknown>"
+// templateFor picks the template source used for a customer. An assigned A/B variant
+// wins; otherwise the variant in the customer's language, then the campaign's fallback
+// language, then the base template.
+func (s *CampaignService) templateFor(campaign *model.Campaign, customer *model.Customer) templateChoice {
+    if v := AssignVariant(campaign, customer.ID); v != nil {
+        return templateChoice{Source: v.Template, Language: DefaultLanguage, Variant: v.Label}
     }
-    return strings.ReplaceAll(template, placeholder, value)
+    if source, ok := campaign.TemplateVariants[customer.Language]; ok && customer.Language != "" {
+        return templateChoice{Source: source, Language: customer.Language}
+    }
+    if source, ok := campaign.TemplateVariants[campaign.FallbackLanguage]; ok && campaign.FallbackLanguage != "" {
+        return templateChoice{Source: source, Language: campaign.FallbackLanguage}
+    }
+    return templateChoice{Source: campaign.BaseTemplate, Language: DefaultLanguage}
+}
+
+// messageData builds the values placeholders are resolved against
+func (s *CampaignService) messageData(campaign *model.Campaign, customer *model.Customer) template.Data {
+    return template.CustomerData(customer).With(template.CampaignPrefix, campaign.Variables)
 }
 
 
@@ -94,10 +220,19 @@ func (s *CampaignService) SendCampaign(campaignID int, customerIDs []int) (*Send
         return nil, err
     }
 
-    if campaign.Status != "draft" && campaign.Status != "scheduled" && campaign.Status != "sending" {
-        return nil, fmt.Errorf("campaign cannot be sent in status: %s", campaign.Status)
+    // Moving to sending first means a second send of the same campaign is rejected
+    if err := s.transition(campaign, campaignstate.Sending); err != nil {
+        return nil, err
     }
+    return s.send(campaign, customerIDs)
+}
 
+// send creates and queues the messages of a campaign that is already sending. Messages are
+// created idempotently, so sending to the same customers again queues their existing messages
+// and the worker skips those already handled.
+func (s *CampaignService) send(campaign *model.Campaign, customerIDs []int) (*SendCampaignResult, error) {
+    campaignID := campaign.ID
+    var err error
     result := &SendCampaignResult{
         CampaignID:     campaignID,
         MessagesQueued: 0,
@@ -105,7 +240,44 @@ func (s *CampaignService) SendCampaign(campaignID int, customerIDs []int) (*Send
         MessageIDs:     []int{},
     }
 
+    suppressed := map[int]string{}
+    if s.SuppressionRepo != nil {
+        suppressed, err = s.SuppressionRepo.Suppressed(customerIDs, campaign.Channel)
+        if err != nil {
+            return nil, err
+        }
+    }
+
+    var consented map[int]bool
+    if s.ConsentRepo != nil {
+        consented, err = s.ConsentRepo.Consented(customerIDs, campaign.Channel)
+        if err != nil {
+            return nil, err
+        }
+    }
+
+    capped, err := s.FrequencyCap.Capped(s.CampaignRepo, campaign, customerIDs, true)
+    if err != nil {
+        return nil, err
+    }
+
+    toQueue := []int{}
     for _, customerID := range customerIDs {
+        if s.ConsentRepo != nil && !consented[customerID] {
+            result.NoConsent++
+            continue
+        }
+
+        // Erased customers are never messaged again
+        customer, err := s.CustomerRepo.GetByID(customerID)
+        if err != nil {
+            log.Println("⚠️ failed to load customer", customerID, ":", err)
+            continue
+        }
+        if customer != nil && customer.ErasedAt != nil {
+            continue
+        }
+
         // Idempotent create (returns existing if already exists)
         msg, err := s.CampaignRepo.CreateOutboundMessage(campaignID, customerID)
         if err != nil {
@@ -117,37 +289,64 @@ func (s *CampaignService) SendCampaign(campaignID int, customerIDs []int) (*Send
             continue
         }
 
+        // Opted-out customers keep a message row so stats show them, but nothing is sent
+        if reason, ok := suppressed[customerID]; ok {
+            if msg.Status != "sent" {
+                if err := s.CampaignRepo.UpdateOutboundMessageStatus(msg.ID, "suppressed", reason); err != nil {
+                    log.Println("⚠️ failed to mark message", msg.ID, "suppressed:", err)
+                    continue
+                }
+            }
+            result.Suppressed++
+            continue
+        }
+
         // Render content if empty
         if msg.RenderedContent == "" {
-            rendered, err := s.RenderPreview(campaignID, customerID, nil)
-            if err != nil {
+            if customer == nil {
+                log.Println("⚠️ customer", customerID, "not found")
+                continue
+            }
+
+            if err := s.RenderOutbound(campaign, customer, msg); err != nil {
                 log.Println("⚠️ failed to render message for customer", customerID, ":", err)
                 continue
             }
 
-            if err := s.CampaignRepo.UpdateOutboundMessageContent(msg.ID, rendered); err != nil {
+            if err := s.CampaignRepo.UpdateOutboundMessageContent(msg); err != nil {
                 log.Println("⚠️ failed to update rendered content:", err)
                 continue
             }
-            msg.RenderedContent = rendered
         }
 
+        // Customers who had enough messages lately wait, or miss this campaign
+        if until, ok := capped[customerID]; ok && msg.Status == "pending" {
+            if err := applyFrequencyCap(s.CampaignRepo, campaign, msg, until); err != nil {
+                log.Println("⚠️ failed to apply frequency cap to message", msg.ID, ":", err)
+                continue
+            }
+            result.Capped++
+            continue
+        }
+
+        toQueue = append(toQueue, msg.ID)
+    }
 
-        // Always queue the message
-        if err := s.Queue.Publish("campaign_sends", msg.ID); err != nil {
-            log.Println("⚠️ failed to enqueue message ID", msg.ID, ":", err)
+    // Queue only once every message exists, so a fast delivery cannot find the campaign
+    // without unfinished messages and complete it early
+    for _, id := range toQueue {
+        if err := s.Queue.Publish("campaign_sends", id); err != nil {
+            log.Println("⚠️ failed to enqueue message ID", id, ":", err)
             continue
         }
 
-        result.MessageIDs = append(result.MessageIDs, msg.ID)
+        result.MessageIDs = append(result.MessageIDs, id)
         result.MessagesQueued++
     }
 
-
-    if campaign.Status != "sending" {
-        if err := s.CampaignRepo.UpdateStatus(campaignID, "sending"); err != nil {
-            return result, err
-        }
+    // nothing may have been queued, e.g. when every customer is suppressed
+    if _, err := s.Completion.Check(s.CampaignRepo, campaignID); err != nil {
+        log.Println("⚠️ failed to check completion of campaign", campaignID, ":", err)
     }
 
     return result, nil
@@ -156,23 +355,148 @@ func (s *CampaignService) SendCampaign(campaignID int, customerIDs []int) (*Send
 
 
 
-func (s *CampaignService) CreateCampaign(name, channel, baseTemplate string, scheduledAt *string) (*model.Campaign, error) {
-    c := &model.Campaign{
-        Name:         name,
-        Channel:      channel,
-        BaseTemplate: baseTemplate,
-        Status:       "draft",
+// validateTemplate rejects templates that are empty, malformed or reference unknown placeholders.
+// field names the request field the template came from.
+func validateTemplate(field, tmpl string, variables map[string]string) error {
+    if strings.TrimSpace(tmpl) == "" {
+        return appErrors.NewInvalidTemplate(field, template.Errors{{Offset: 0, Message: "template cannot be empty"}})
     }
+    if problems := template.Validate(tmpl, variables); len(problems) > 0 {
+        return appErrors.NewInvalidTemplate(field, problems)
+    }
+    return nil
+}
 
-    if scheduledAt != nil {
-        // parse scheduledAt string into time.Time
-        t, err := time.Parse(time.RFC3339, *scheduledAt)
+// validateCampaign checks whichever template the campaign sends with
+func (s *CampaignService) validateCampaign(c *model.Campaign) error {
+    if c.FrequencyCapPolicy != "" && !frequencyCapPolicies[c.FrequencyCapPolicy] {
+        return appErrors.NewValidation("frequency_cap_policy", "must be defer or skip")
+    }
+    if c.QuietHours != nil {
+        if err := validateQuietHours(c.QuietHours); err != nil {
+            return err
+        }
+    }
+    if err := validateAudience(c.Audience); err != nil {
+        return err
+    }
+    if c.Status == campaignstate.Scheduled && c.Audience == nil {
+        return appErrors.NewValidation("audience", "is required to schedule a campaign")
+    }
+    if c.WhatsAppTemplateID != nil {
+        if len(c.Variants) > 0 {
+            return appErrors.NewValidation("variants", "cannot be combined with a whatsapp template")
+        }
+        return s.validateWhatsAppCampaign(c)
+    }
+    if len(c.Variants) > 0 {
+        return validateVariants(c)
+    }
+    return validateTemplateVariants(c)
+}
+
+// validateTemplateVariants checks the base template and every language variant. The base
+// template may be left empty when a fallback language guarantees every customer a variant.
+func validateTemplateVariants(c *model.Campaign) error {
+    if c.FallbackLanguage != "" {
+        if _, ok := c.TemplateVariants[c.FallbackLanguage]; !ok {
+            return appErrors.NewValidation("fallback_language", fmt.Sprintf("no template variant for %q", c.FallbackLanguage))
+        }
+    }
+    if c.BaseTemplate != "" || c.FallbackLanguage == "" {
+        if err := validateTemplate("base_template", c.BaseTemplate, c.Variables); err != nil {
+            return err
+        }
+    }
+
+    languages := make([]string, 0, len(c.TemplateVariants))
+    for language := range c.TemplateVariants {
+        languages = append(languages, language)
+    }
+    sort.Strings(languages)
+    for _, language := range languages {
+        if strings.TrimSpace(language) == "" {
+            return appErrors.NewValidation("template_variants", "language cannot be empty")
+        }
+        if err := validateTemplate("template_variants."+language, c.TemplateVariants[language], c.Variables); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// CampaignInput is the body accepted by POST /campaigns
+type CampaignInput struct {
+    Name               string            `json:"name"`
+    Channel            string            `json:"channel"`
+    BaseTemplate       string            `json:"base_template"`
+    Variables          map[string]string `json:"variables"`
+    TemplateVariants   map[string]string `json:"template_variants"`
+    FallbackLanguage   string            `json:"fallback_language"`
+    Variants           []model.CampaignVariant `json:"variants"`
+    WhatsAppTemplateID *int              `json:"whatsapp_template_id"`
+    WhatsAppParameters []string          `json:"whatsapp_parameters"`
+    FrequencyCapPolicy string            `json:"frequency_cap_policy"`
+    QuietHours         *model.QuietHours `json:"quiet_hours"`
+    ScheduledAt        *string           `json:"scheduled_at"`
+    Audience           *model.CampaignAudience `json:"audience"`
+}
+
+// CampaignPatch holds the fields PATCH /campaigns/{id} may change; nil fields are left untouched.
+// An empty scheduled_at unschedules the campaign and an empty audience clears it.
+type CampaignPatch struct {
+    Name               *string            `json:"name"`
+    Channel            *string            `json:"channel"`
+    ScheduledAt        *string            `json:"scheduled_at"`
+    BaseTemplate       *string            `json:"base_template"`
+    Variables          *map[string]string `json:"variables"`
+    TemplateVariants   *map[string]string `json:"template_variants"`
+    FallbackLanguage   *string            `json:"fallback_language"`
+    Variants           *[]model.CampaignVariant `json:"variants"`
+    WhatsAppTemplateID *int               `json:"whatsapp_template_id"`
+    WhatsAppParameters *[]string          `json:"whatsapp_parameters"`
+    FrequencyCapPolicy *string            `json:"frequency_cap_policy"`
+    QuietHours         *model.QuietHours  `json:"quiet_hours"`
+    Audience           *model.CampaignAudience `json:"audience"`
+}
+
+func (s *CampaignService) CreateCampaign(in CampaignInput) (*model.Campaign, error) {
+    c := &model.Campaign{
+        Name:               in.Name,
+        Channel:            in.Channel,
+        BaseTemplate:       in.BaseTemplate,
+        Variables:          in.Variables,
+        TemplateVariants:   in.TemplateVariants,
+        FallbackLanguage:   in.FallbackLanguage,
+        Variants:           in.Variants,
+        WhatsAppTemplateID: in.WhatsAppTemplateID,
+        WhatsAppParameters: in.WhatsAppParameters,
+        FrequencyCapPolicy: in.FrequencyCapPolicy,
+        QuietHours:         in.QuietHours,
+        Audience:           audienceOrNil(in.Audience),
+        Status:             "draft",
+    }
+    if c.FrequencyCapPolicy == "" {
+        c.FrequencyCapPolicy = "defer"
+    }
+    if in.ScheduledAt != nil && c.Audience != nil {
+        // the scheduler sends it to its audience once due
+        if err := s.reschedule(c, *in.ScheduledAt); err != nil {
+            return nil, err
+        }
+    } else if in.ScheduledAt != nil {
+        // without an audience there is nothing to dispatch, so it stays a draft
+        t, err := time.Parse(time.RFC3339, *in.ScheduledAt)
         if err != nil {
             return nil, err
         }
         c.ScheduledAt = &t
     }
 
+    if err := s.validateCampaign(c); err != nil {
+        return nil, err
+    }
+
     if err := s.CampaignRepo.Create(c); err != nil {
         return nil, err
     }
@@ -180,6 +504,125 @@ func (s *CampaignService) CreateCampaign(name, channel, baseTemplate string, sch
     return c, nil
 }
 
+// UpdateCampaign applies a partial update, re-validating the resulting template and variables.
+// Only draft and scheduled campaigns can be edited; setting or clearing scheduled_at moves
+// the campaign between the two.
+func (s *CampaignService) UpdateCampaign(id int, patch CampaignPatch) (*model.Campaign, error) {
+    c, err := s.CampaignRepo.GetByID(id)
+    if err != nil {
+        return nil, err
+    }
+    if c.Status != campaignstate.Draft && c.Status != campaignstate.Scheduled {
+        return nil, appErrors.NewConflict("status", fmt.Sprintf("campaign is %s and can only be edited while draft or scheduled", c.Status))
+    }
+
+    if err := s.applyPatch(c, patch); err != nil {
+        return nil, err
+    }
+    if err := s.validateCampaign(c); err != nil {
+        return nil, err
+    }
+    if err := s.CampaignRepo.Update(c); err != nil {
+        return nil, err
+    }
+    return c, nil
+}
+
+// applyPatch copies the fields set in patch onto c. It leaves validating the result to the caller.
+func (s *CampaignService) applyPatch(c *model.Campaign, patch CampaignPatch) error {
+    if patch.Name != nil {
+        c.Name = *patch.Name
+    }
+    if patch.Channel != nil {
+        if *patch.Channel != "sms" && *patch.Channel != "whatsapp" {
+            return appErrors.NewValidation("channel", "must be sms or whatsapp")
+        }
+        c.Channel = *patch.Channel
+    }
+    if patch.ScheduledAt != nil {
+        if err := s.reschedule(c, *patch.ScheduledAt); err != nil {
+            return err
+        }
+    }
+    if patch.BaseTemplate != nil {
+        c.BaseTemplate = *patch.BaseTemplate
+    }
+    if patch.Variables != nil {
+        c.Variables = *patch.Variables
+    }
+    if patch.TemplateVariants != nil {
+        c.TemplateVariants = *patch.TemplateVariants
+    }
+    if patch.FallbackLanguage != nil {
+        c.FallbackLanguage = *patch.FallbackLanguage
+    }
+    if patch.Variants != nil {
+        c.Variants = *patch.Variants
+    }
+    if patch.WhatsAppTemplateID != nil {
+        c.WhatsAppTemplateID = patch.WhatsAppTemplateID
+    }
+    if patch.WhatsAppParameters != nil {
+        c.WhatsAppParameters = *patch.WhatsAppParameters
+    }
+    if patch.FrequencyCapPolicy != nil {
+        c.FrequencyCapPolicy = *patch.FrequencyCapPolicy
+    }
+    if patch.QuietHours != nil {
+        c.QuietHours = patch.QuietHours
+    }
+    if patch.Audience != nil {
+        c.Audience = audienceOrNil(patch.Audience)
+    }
+    return nil
+}
+
+// reschedule sets or, when scheduledAt is empty, clears a campaign's send time, moving it to
+// scheduled or back to draft
+func (s *CampaignService) reschedule(c *model.Campaign, scheduledAt string) error {
+    c.ScheduledAt = nil
+    status := campaignstate.Draft
+    if scheduledAt != "" {
+        t, err := time.Parse(time.RFC3339, scheduledAt)
+        if err != nil {
+            return appErrors.NewValidation("scheduled_at", "must be an RFC 3339 time, e.g. 2025-01-31T09:00:00+03:00")
+        }
+        if !t.After(time.Now()) {
+            return appErrors.NewValidation("scheduled_at", "must be in the future")
+        }
+        c.ScheduledAt = &t
+        status = campaignstate.Scheduled
+    }
+    if status != c.Status {
+        if err := campaignstate.Check(c.ID, c.Status, status); err != nil {
+            return err
+        }
+        c.Status = status
+    }
+    return nil
+}
+
+// DeleteCampaign soft-deletes a draft campaign. Campaigns past draft are kept for their history
+// and return ErrConflict; a scheduled campaign has to be unscheduled first.
+func (s *CampaignService) DeleteCampaign(id int) error {
+    c, err := s.CampaignRepo.GetByID(id)
+    if err != nil {
+        return err
+    }
+    conflict := appErrors.NewConflict("status", fmt.Sprintf("campaign is %s and only drafts can be deleted", c.Status))
+    if c.Status != campaignstate.Draft {
+        return conflict
+    }
+    deleted, err := s.CampaignRepo.Delete(id)
+    if err != nil {
+        return err
+    }
+    if !deleted {
+        return conflict // it left draft since it was loaded
+    }
+    return nil
+}
+
 // ListCampaigns fetches campaigns with pagination
 func (s *CampaignService) ListCampaigns(page, pageSize int, channel, status string) ([]model.Campaign, map[string]int, error) {
     if page < 1 {
@@ -232,10 +675,10 @@ func (s *CampaignService) GetCampaignDetailsWithStats(campaignID int) (*Campaign
 
     // Fetch outbound message counts by status
     query := `
-        SELECT status, COUNT(*) 
+        SELECT status, COALESCE(variant, ''), COUNT(*), COALESCE(SUM(segments), 0)
         FROM outbound_messages
         WHERE campaign_id = $1
-        GROUP BY status
+        GROUP BY status, variant
     `
     rows, err := s.OutboundRepo.DB.Query(query, campaignID)
     if err != nil {
@@ -246,44 +689,101 @@ func (s *CampaignService) GetCampaignDetailsWithStats(campaignID int) (*Campaign
 
     // initialize stats map
     stats := map[string]int{
-        "total":   0,
-        "pending": 0,
-        "sending": 0,
-        "sent":    0,
-        "failed":  0,
+        "total":         0,
+        "pending":       0,
+        "sending":       0,
+        "sent":          0,
+        "failed":        0,
+        "suppressed":    0,
+        "deferred":      0,
+        "held":          0,
+        "skipped":       0,
+        "retrying":      0,
+        "paused":        0,
+        "cancelled":     0,
+        "segments_sent": 0,
+    }
+
+    // per A/B variant delivery counts
+    variantStats := map[string]map[string]int{}
+    for _, v := range campaign.Variants {
+        variantStats[v.Label] = map[string]int{"total": 0, "pending": 0, "sent": 0, "failed": 0}
     }
 
     for rows.Next() {
-        var status string
-        var count int
-        if err := rows.Scan(&status, &count); err != nil {
+        var status, variant string
+        var count, segments int
+        if err := rows.Scan(&status, &variant, &count, &segments); err != nil {
             log.Println("Failed to scan row:", err)
             return nil, err
         }
-        log.Printf("Status row: %s => %d\n", status, count)
+        log.Printf("Status row: %s (variant %q) => %d\n", status, variant, count)
 
         if _, ok := stats[status]; ok {
-            stats[status] = count
+            stats[status] += count
+        }
+        if status == "sent" {
+            stats["segments_sent"] += segments
         }
         stats["total"] += count
-    }
-
-    log.Printf("Final stats map: %+v\n", stats)
 
-    return &CampaignDetails{
-        ID:           campaign.ID,
-        Name:         campaign.Name,
-        Channel:      campaign.Channel,
-        Status:       campaign.Status,
-        BaseTemplate: campaign.BaseTemplate,
-        ScheduledAt:  campaign.ScheduledAt,
-        CreatedAt:    campaign.CreatedAt,
-        UpdatedAt:    campaign.UpdatedAt,
-        Stats:        stats,
-    }, nil
-}
+        if variant != "" {
+            if variantStats[variant] == nil {
+                variantStats[variant] = map[string]int{"total": 0, "pending": 0, "sent": 0, "failed": 0}
+            }
+            variantStats[variant][status] += count
+            variantStats[variant]["total"] += count
+        }
+    }
 
+    // clicks on tracked links; unique_clicks counts messages with at least one click
+    stats["clicks"], stats["unique_clicks"] = 0, 0
+    clickQuery := `
+        SELECT COALESCE(om.variant, ''), COUNT(lc.id), COUNT(DISTINCT om.id)
+        FROM link_clicks lc
+        JOIN short_links sl ON sl.id = lc.short_link_id
+        JOIN outbound_messages om ON om.id = sl.outbound_message_id
+        WHERE om.campaign_id = $1
+        GROUP BY om.variant
+    `
+    clickRows, err := s.OutboundRepo.DB.Query(clickQuery, campaignID)
+    if err != nil {
+        log.Println("Failed to query link clicks:", err)
+        return nil, err
+    }
+    defer clickRows.Close()
 
+    for clickRows.Next() {
+        var variant string
+        var clicks, unique int
+        if err := clickRows.Scan(&variant, &clicks, &unique); err != nil {
+            return nil, err
+        }
+        stats["clicks"] += clicks
+        stats["unique_clicks"] += unique
+        if vs := variantStats[variant]; vs != nil {
+            vs["clicks"] += clicks
+            vs["unique_clicks"] += unique
+        }
+    }
 
+    log.Printf("Final stats map: %+v\n", stats)
 
+    details := &CampaignDetails{
+        Campaign:         campaign,
+        Stats:            stats,
+        ClickThroughRate: clickThroughRate(stats["unique_clicks"], stats["sent"]),
+    }
+    if len(variantStats) > 0 {
+        details.VariantStats = variantStats
+    }
+    return details, nil
+}
 
+// clickThroughRate is the percentage of sent messages that were clicked, to two decimals
+func clickThroughRate(clicked, sent int) float64 {
+    if sent == 0 {
+        return 0
+    }
+    return math.Round(float64(clicked)*10000/float64(sent)) / 100
+}
@@ -0,0 +1,80 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+func TestLanguageVariantSelection(t *testing.T) {
+	svc := &service.CampaignService{}
+	campaign := &model.Campaign{
+		Channel:      "sms",
+		BaseTemplate: "Hello {first_name}",
+		TemplateVariants: map[string]string{
+			"en": "Hi {first_name}",
+			"sw": "Habari {first_name}",
+		},
+	}
+
+	cases := []struct {
+		language string
+		fallback string
+		want     string
+	}{
+		{"sw", "", "Habari Amina"},
+		{"en", "sw", "Hi Amina"},
+		{"fr", "sw", "Habari Amina"},
+		{"fr", "", "Hello Amina"},
+		{"", "en", "Hi Amina"},
+	}
+
+	for _, tc := range cases {
+		campaign.FallbackLanguage = tc.fallback
+		got, err := svc.RenderMessage(campaign, &model.Customer{FirstName: "Amina", Language: tc.language})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != tc.want {
+			t.Errorf("language %q fallback %q: expected %q, got %q", tc.language, tc.fallback, tc.want, got)
+		}
+	}
+}
+
+func TestLanguageVariantValidation(t *testing.T) {
+	svc := &service.CampaignService{CampaignRepo: &MockCampaignPaginationRepo{}}
+
+	_, err := svc.CreateCampaign(service.CampaignInput{
+		Name:             "Bilingual",
+		Channel:          "sms",
+		TemplateVariants: map[string]string{"en": "Hi {first_name}", "sw": "Habari {jina}"},
+		FallbackLanguage: "en",
+	})
+	var invalid *appErrors.ErrInvalidTemplate
+	if !errors.As(err, &invalid) || invalid.Field != "template_variants.sw" {
+		t.Fatalf("expected invalid sw variant, got %v", err)
+	}
+
+	_, err = svc.CreateCampaign(service.CampaignInput{
+		Name:             "Bilingual",
+		Channel:          "sms",
+		TemplateVariants: map[string]string{"en": "Hi {first_name}"},
+		FallbackLanguage: "sw",
+	})
+	var validation *appErrors.ErrValidation
+	if !errors.As(err, &validation) || validation.Field != "fallback_language" {
+		t.Fatalf("expected missing fallback variant to be rejected, got %v", err)
+	}
+
+	if _, err := svc.CreateCampaign(service.CampaignInput{
+		Name:             "Bilingual",
+		Channel:          "sms",
+		TemplateVariants: map[string]string{"en": "Hi {first_name}", "sw": "Habari {first_name}"},
+		FallbackLanguage: "en",
+	}); err != nil {
+		t.Fatalf("expected campaign without base template to be accepted, got %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/repository"
 	"github.com/unclebandit/smsleopard-backend/internal/service"
 )
 
@@ -48,9 +49,17 @@ func (m *MockCampaignPaginationRepo) Update(c *model.Campaign) error {
 	return nil
 }
 
-func (m *MockCampaignPaginationRepo) UpdateStatus(id int, status string) error {
+func (m *MockCampaignPaginationRepo) Delete(id int) (bool, error) {
+	return true, nil
+}
+
+func (m *MockCampaignPaginationRepo) TransitionStatus(id int, from, to string) (bool, error) {
 	// do nothing, just stub
-	return nil
+	return true, nil
+}
+
+func (m *MockCampaignPaginationRepo) CompleteCampaign(id int, status string, completedAt time.Time) (bool, error) {
+	return true, nil
 }
 
 func TestPagination(t *testing.T) {
@@ -129,21 +138,31 @@ func (m *MockCampaignPaginationRepo) GetCampaignStats(campaignID int) (map[strin
     return map[string]int{"pending": 1, "sent": 0, "failed": 0}, nil
 }
 
-
-func (m *MockCampaignRepo) GetOutboundMessageByID(id int) (*model.OutboundMessage, error) {
+func (m *MockCampaignPaginationRepo) GetOutboundMessageByID(id int) (*model.OutboundMessage, error) {
     return &model.OutboundMessage{
         ID: id,
         RenderedContent: "Test message",
     }, nil
 }
 
-func (m *MockCampaignPaginationRepo) GetOutboundMessageByID(id int) (*model.OutboundMessage, error) {
-    return &model.OutboundMessage{
-        ID: id,
-        RenderedContent: "Test message",
-    }, nil
+func (m *MockCampaignPaginationRepo) ClaimOutboundMessage(id int) (bool, error) { return true, nil }
+
+func (m *MockCampaignPaginationRepo) UpdateOutboundMessageContent(msg *model.OutboundMessage) error {
+    return nil
+}
+
+func (m *MockCampaignPaginationRepo) RecentSends(customerIDs []int, channel string, since time.Time, excludeCampaignID int, includePending bool) (map[int]repository.RecentSends, error) {
+    return map[int]repository.RecentSends{}, nil
 }
 
-func (m *MockCampaignPaginationRepo) UpdateOutboundMessageContent(id int, content string) error {
+func (m *MockCampaignPaginationRepo) HoldOutboundMessage(id int, status string, notBefore time.Time, reason string) error {
     return nil
 }
+
+func (m *MockCampaignPaginationRepo) ReleaseDueMessages(now time.Time) ([]int, error) {
+    return []int{}, nil
+}
+
+func (m *MockCampaignPaginationRepo) UpdateCampaignMessageStatuses(campaignID int, from []string, to, reason string) ([]int, error) {
+    return []int{}, nil
+}
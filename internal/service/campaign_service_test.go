@@ -7,6 +7,7 @@ import (
 
 	"github.com/unclebandit/smsleopard-backend/internal/model"
 	"github.com/unclebandit/smsleopard-backend/internal/repository"
+	"github.com/unclebandit/smsleopard-backend/internal/tagexpr"
 
 )
 
@@ -46,6 +47,28 @@ func (m *MockCustomerRepo) ListAll() ([]model.Customer, error) {
 	}, nil
 }
 
+func (m *MockCustomerRepo) UpdateAttributes(id int, attributes map[string]string) error {
+	return nil
+}
+
+func (m *MockCustomerRepo) List(offset, limit int, location, product string) ([]model.Customer, int, error) {
+	return []model.Customer{}, 0, nil
+}
+
+func (m *MockCustomerRepo) Create(c *model.Customer) error { return nil }
+func (m *MockCustomerRepo) Update(c *model.Customer) error { return nil }
+func (m *MockCustomerRepo) Delete(id int) error            { return nil }
+
+func (m *MockCustomerRepo) UpsertByPhone(c *model.Customer) (bool, error) { return true, nil }
+func (m *MockCustomerRepo) Messages(customerID int) ([]model.OutboundMessage, error) {
+	return []model.OutboundMessage{}, nil
+}
+func (m *MockCustomerRepo) Erase(id int) error { return nil }
+func (m *MockCustomerRepo) AddTags(customerID int, tags []string) error { return nil }
+func (m *MockCustomerRepo) RemoveTag(customerID int, tag string) (bool, error) { return false, nil }
+func (m *MockCustomerRepo) Tags(customerID int) ([]string, error) { return []string{}, nil }
+func (m *MockCustomerRepo) CustomerIDsByTags(expr tagexpr.Expr) ([]int, error) { return []int{}, nil }
+
 
 func (m *MockCampaignRepo) GetByID(id int) (*model.Campaign, error) {
 	return &model.Campaign{
@@ -56,10 +79,12 @@ func (m *MockCampaignRepo) GetByID(id int) (*model.Campaign, error) {
 // Stub implementations to satisfy interface
 func (m *MockCampaignRepo) Create(c *model.Campaign) error          { return nil }
 func (m *MockCampaignRepo) Update(c *model.Campaign) error          { return nil }
+func (m *MockCampaignRepo) Delete(id int) (bool, error)                    { return true, nil }
 func (m *MockCampaignRepo) ListCampaigns(offset, limit int, channel, status string) ([]*model.Campaign, int, error) {
 	return []*model.Campaign{}, 0, nil
 }
-func (m *MockCampaignRepo) UpdateStatus(id int, status string) error { return nil }
+func (m *MockCampaignRepo) TransitionStatus(id int, from, to string) (bool, error) { return true, nil }
+func (m *MockCampaignRepo) CompleteCampaign(id int, status string, completedAt time.Time) (bool, error) { return true, nil }
 
 func (s *CampaignService) RenderPreview(campaignID, customerID int, overrideTemplate *string) (string, error) {
     campaign, err := s.CampaignRepo.GetByID(campaignID)
@@ -142,4 +167,29 @@ func (m *MockCampaignRepo) GetCampaignStats(campaignID int) (map[string]int, err
 	return map[string]int{"pending": 1, "sent": 0, "failed": 0}, nil
 }
 
+func (m *MockCampaignRepo) GetOutboundMessageByID(id int) (*model.OutboundMessage, error) {
+	return &model.OutboundMessage{
+		ID: id,
+		RenderedContent: "Test message",
+	}, nil
+}
+
+func (m *MockCampaignRepo) ClaimOutboundMessage(id int) (bool, error) { return true, nil }
+
+func (m *MockCampaignRepo) RecentSends(customerIDs []int, channel string, since time.Time, excludeCampaignID int, includePending bool) (map[int]repository.RecentSends, error) {
+	return map[int]repository.RecentSends{}, nil
+}
+
+func (m *MockCampaignRepo) HoldOutboundMessage(id int, status string, notBefore time.Time, reason string) error {
+	return nil
+}
+
+func (m *MockCampaignRepo) ReleaseDueMessages(now time.Time) ([]int, error) {
+	return []int{}, nil
+}
+
+func (m *MockCampaignRepo) UpdateCampaignMessageStatuses(campaignID int, from []string, to, reason string) ([]int, error) {
+	return []int{}, nil
+}
+
 
@@ -0,0 +1,128 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+// MockEditRepo stores a single campaign and hides it once soft-deleted
+type MockEditRepo struct {
+	MockCampaignPaginationRepo
+	campaign model.Campaign
+	deleted  bool
+}
+
+func newMockEditRepo(status string) *MockEditRepo {
+	return &MockEditRepo{campaign: model.Campaign{ID: 1, Name: "Sale", Channel: "sms", Status: status, BaseTemplate: "Hi {first_name}", FrequencyCapPolicy: "defer"}}
+}
+
+func (m *MockEditRepo) GetByID(id int) (*model.Campaign, error) {
+	if id != m.campaign.ID || m.deleted {
+		return nil, appErrors.NewCampaignNotFound(id)
+	}
+	c := m.campaign
+	return &c, nil
+}
+
+func (m *MockEditRepo) Update(c *model.Campaign) error {
+	m.campaign = *c
+	return nil
+}
+
+func (m *MockEditRepo) Delete(id int) (bool, error) {
+	if m.campaign.Status != "draft" || m.deleted {
+		return false, nil
+	}
+	m.deleted = true
+	return true, nil
+}
+
+func TestUpdateCampaign(t *testing.T) {
+	repo := newMockEditRepo("draft")
+	svc := &service.CampaignService{CampaignRepo: repo}
+
+	channel, name := "whatsapp", "Big sale"
+	c, err := svc.UpdateCampaign(1, service.CampaignPatch{Name: &name, Channel: &channel})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Name != "Big sale" || c.Channel != "whatsapp" || c.BaseTemplate != "Hi {first_name}" {
+		t.Errorf("unexpected update result %+v", c)
+	}
+
+	var validation *appErrors.ErrValidation
+	bad := "email"
+	if _, err := svc.UpdateCampaign(1, service.CampaignPatch{Channel: &bad}); !errors.As(err, &validation) || validation.Field != "channel" {
+		t.Errorf("expected unknown channel to be rejected, got %v", err)
+	}
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	if _, err := svc.UpdateCampaign(1, service.CampaignPatch{ScheduledAt: &past}); !errors.As(err, &validation) || validation.Field != "scheduled_at" {
+		t.Errorf("expected past scheduled_at to be rejected, got %v", err)
+	}
+
+	// scheduling and unscheduling move the campaign between draft and scheduled; the scheduler
+	// needs an audience to send to
+	future := time.Now().Add(time.Hour).Format(time.RFC3339)
+	if _, err := svc.UpdateCampaign(1, service.CampaignPatch{ScheduledAt: &future}); !errors.As(err, &validation) || validation.Field != "audience" {
+		t.Errorf("expected scheduling without an audience to be rejected, got %v", err)
+	}
+	audience := &model.CampaignAudience{CustomerIDs: []int{1, 2}}
+	if c, err = svc.UpdateCampaign(1, service.CampaignPatch{ScheduledAt: &future, Audience: audience}); err != nil || c.Status != "scheduled" || c.ScheduledAt == nil {
+		t.Fatalf("expected campaign scheduled, got %+v (%v)", c, err)
+	}
+	none := ""
+	if c, err = svc.UpdateCampaign(1, service.CampaignPatch{ScheduledAt: &none}); err != nil || c.Status != "draft" || c.ScheduledAt != nil {
+		t.Fatalf("expected campaign back in draft, got %+v (%v)", c, err)
+	}
+
+	var conflict *appErrors.ErrConflict
+	repo.campaign.Status = "sending"
+	if _, err := svc.UpdateCampaign(1, service.CampaignPatch{Name: &name}); !errors.As(err, &conflict) || conflict.Field != "status" {
+		t.Errorf("expected a sending campaign not to be editable, got %v", err)
+	}
+}
+
+func TestDeleteCampaign(t *testing.T) {
+	repo := newMockEditRepo("scheduled")
+	svc := &service.CampaignService{CampaignRepo: repo}
+
+	var conflict *appErrors.ErrConflict
+	if err := svc.DeleteCampaign(1); !errors.As(err, &conflict) {
+		t.Fatalf("expected only drafts to be deletable, got %v", err)
+	}
+
+	repo.campaign.Status = "draft"
+	if err := svc.DeleteCampaign(1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var notFound *appErrors.ErrCampaignNotFound
+	if _, err := svc.GetCampaignDetails(1); !errors.As(err, &notFound) {
+		t.Errorf("expected deleted campaign to be gone, got %v", err)
+	}
+	if err := svc.DeleteCampaign(1); !errors.As(err, &notFound) {
+		t.Errorf("expected deleting twice to return not found, got %v", err)
+	}
+}
+
+func TestCreateCampaignWithScheduledAt(t *testing.T) {
+	svc := &service.CampaignService{CampaignRepo: &MockCampaignPaginationRepo{}}
+	future := time.Now().Add(time.Hour).Format(time.RFC3339)
+
+	// without an audience the scheduler has nobody to send to, so it stays a draft
+	c, err := svc.CreateCampaign(service.CampaignInput{Name: "Sale", Channel: "sms", BaseTemplate: "Hi {first_name}", ScheduledAt: &future})
+	if err != nil || c.Status != "draft" || c.ScheduledAt == nil {
+		t.Fatalf("expected a draft keeping scheduled_at, got %+v (%v)", c, err)
+	}
+
+	audience := &model.CampaignAudience{CustomerIDs: []int{1}}
+	c, err = svc.CreateCampaign(service.CampaignInput{Name: "Sale", Channel: "sms", BaseTemplate: "Hi {first_name}", ScheduledAt: &future, Audience: audience})
+	if err != nil || c.Status != "scheduled" {
+		t.Fatalf("expected a scheduled campaign, got %+v (%v)", c, err)
+	}
+}
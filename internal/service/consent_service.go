@@ -0,0 +1,62 @@
+// internal/service/consent_service.go
+package service
+
+import (
+	"strings"
+	"time"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/repository"
+)
+
+// ConsentService records the marketing consent campaign sends require
+type ConsentService struct {
+	Repo repository.ConsentRepositoryInterface
+}
+
+// consentChannel normalises and checks a channel name
+func consentChannel(channel string) (string, error) {
+	channel = strings.ToLower(strings.TrimSpace(channel))
+	if channel != "sms" && channel != "whatsapp" {
+		return "", appErrors.NewValidation("channel", "must be sms or whatsapp")
+	}
+	return channel, nil
+}
+
+// Grant records a customer's consent. GrantedAt defaults to now and may be set to an earlier
+// time when consent captured elsewhere is being recorded.
+func (s *ConsentService) Grant(c *model.Consent) error {
+	channel, err := consentChannel(c.Channel)
+	if err != nil {
+		return err
+	}
+	c.Channel = channel
+	c.Source = strings.TrimSpace(c.Source)
+	if c.Source == "" {
+		return appErrors.NewValidation("source", "is required")
+	}
+	now := time.Now()
+	if c.GrantedAt.IsZero() {
+		c.GrantedAt = now
+	}
+	if c.GrantedAt.After(now) {
+		return appErrors.NewValidation("granted_at", "cannot be in the future")
+	}
+	c.RevokedAt = nil
+	return s.Repo.Grant(c)
+}
+
+// Revoke ends a customer's consent for a channel, returning ErrConsentNotFound when none is active
+func (s *ConsentService) Revoke(customerID int, channel string) (*model.Consent, error) {
+	channel, err := consentChannel(channel)
+	if err != nil {
+		return nil, err
+	}
+	return s.Repo.Revoke(customerID, channel)
+}
+
+// History lists every consent a customer granted, including revoked ones
+func (s *ConsentService) History(customerID int) ([]model.Consent, error) {
+	return s.Repo.ListByCustomer(customerID)
+}
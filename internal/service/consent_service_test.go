@@ -0,0 +1,129 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+// MockConsentRepo keeps consent history in memory
+type MockConsentRepo struct {
+	consents []model.Consent
+}
+
+func (m *MockConsentRepo) active(customerID int, channel string) int {
+	for i, c := range m.consents {
+		if c.CustomerID == customerID && c.Channel == channel && c.RevokedAt == nil {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *MockConsentRepo) Grant(c *model.Consent) error {
+	if i := m.active(c.CustomerID, c.Channel); i >= 0 {
+		*c = m.consents[i]
+		return nil
+	}
+	c.ID = len(m.consents) + 1
+	m.consents = append(m.consents, *c)
+	return nil
+}
+
+func (m *MockConsentRepo) Revoke(customerID int, channel string) (*model.Consent, error) {
+	i := m.active(customerID, channel)
+	if i < 0 {
+		return nil, appErrors.NewConsentNotFound(customerID, channel)
+	}
+	now := time.Now()
+	m.consents[i].RevokedAt = &now
+	revoked := m.consents[i]
+	return &revoked, nil
+}
+
+func (m *MockConsentRepo) ListByCustomer(customerID int) ([]model.Consent, error) {
+	consents := []model.Consent{}
+	for _, c := range m.consents {
+		if c.CustomerID == customerID {
+			consents = append(consents, c)
+		}
+	}
+	return consents, nil
+}
+
+func (m *MockConsentRepo) Consented(customerIDs []int, channel string) (map[int]bool, error) {
+	consented := map[int]bool{}
+	for _, id := range customerIDs {
+		if m.active(id, channel) >= 0 {
+			consented[id] = true
+		}
+	}
+	return consented, nil
+}
+
+func TestConsentGrantAndRevoke(t *testing.T) {
+	svc := &service.ConsentService{Repo: &MockConsentRepo{}}
+
+	cases := map[string]model.Consent{
+		"channel":    {CustomerID: 1, Channel: "email", Source: "web_form"},
+		"source":     {CustomerID: 1, Channel: "sms"},
+		"granted_at": {CustomerID: 1, Channel: "sms", Source: "web_form", GrantedAt: time.Now().Add(time.Hour)},
+	}
+	for field, c := range cases {
+		var validation *appErrors.ErrValidation
+		if err := svc.Grant(&c); !errors.As(err, &validation) || validation.Field != field {
+			t.Errorf("expected validation error on %s, got %v", field, err)
+		}
+	}
+
+	first := &model.Consent{CustomerID: 1, Channel: "SMS", Source: "web_form"}
+	if err := svc.Grant(first); err != nil || first.GrantedAt.IsZero() {
+		t.Fatalf("expected consent with a grant time, got %+v (%v)", first, err)
+	}
+
+	// granting again keeps the original record
+	again := &model.Consent{CustomerID: 1, Channel: "sms", Source: "ussd"}
+	if err := svc.Grant(again); err != nil || again.ID != first.ID || again.Source != "web_form" {
+		t.Errorf("expected existing consent to be kept, got %+v (%v)", again, err)
+	}
+
+	revoked, err := svc.Revoke(1, "sms")
+	if err != nil || revoked.RevokedAt == nil {
+		t.Fatalf("expected revoked consent, got %+v (%v)", revoked, err)
+	}
+	var notFound *appErrors.ErrConsentNotFound
+	if _, err := svc.Revoke(1, "sms"); !errors.As(err, &notFound) {
+		t.Errorf("expected no active consent, got %v", err)
+	}
+
+	history, _ := svc.History(1)
+	if len(history) != 1 {
+		t.Errorf("expected revoked consent to stay in history, got %d records", len(history))
+	}
+}
+
+func TestSendCampaignRequiresConsent(t *testing.T) {
+	consents := &MockConsentRepo{}
+	consents.Grant(&model.Consent{CustomerID: 1, Channel: "sms", Source: "web_form"})
+	consents.Grant(&model.Consent{CustomerID: 2, Channel: "whatsapp", Source: "web_form"})
+
+	q := &MockQueue{}
+	svc := &service.CampaignService{
+		CampaignRepo: &MockSendRepo{},
+		CustomerRepo: &MockCustomerRepo{},
+		ConsentRepo:  consents,
+		Queue:        q,
+	}
+
+	result, err := svc.SendCampaign(1, []int{1, 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.MessagesQueued != 1 || result.NoConsent != 1 || len(q.published) != 1 {
+		t.Errorf("expected 1 queued and 1 skipped, got %d queued, %d skipped", result.MessagesQueued, result.NoConsent)
+	}
+}
@@ -0,0 +1,268 @@
+// internal/service/customer_import_service.go
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/phone"
+	"github.com/unclebandit/smsleopard-backend/internal/repository"
+	"github.com/unclebandit/smsleopard-backend/internal/template"
+)
+
+// importColumns are the customer fields a CSV header may name, besides attr.<key>
+var importColumns = map[string]bool{
+	"phone": true, "first_name": true, "last_name": true, "location": true, "preferred_product": true, "language": true, "timezone": true,
+}
+
+// importOutcomes are the row outcomes a report can be filtered by
+var importOutcomes = map[string]bool{"accepted": true, "updated": true, "rejected": true}
+
+// importBatchSize is how many rows are upserted and recorded per transaction
+const importBatchSize = 500
+
+// CustomerImportService streams CSV files into the customer table, upserting by phone
+type CustomerImportService struct {
+	Jobs repository.ImportJobRepositoryInterface
+
+	// Phones converts phones to E.164 so rows match stored customers; nil uses phone.Default
+	Phones *phone.Normalizer
+}
+
+// Import reads a CSV with a header row one record at a time, so files of any size use
+// constant memory. Rows are upserted by phone in batches and their outcomes recorded on
+// the job. A bad header is rejected before a job is created; a database failure part way
+// through leaves the job in the failed state with the batches saved so far.
+func (s *CustomerImportService) Import(src io.Reader, filename string) (*model.ImportJob, error) {
+	job, reader, columns, err := s.begin(src, filename)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.run(job, reader, columns); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Start checks the header and creates the job like Import, then imports the rows in the
+// background and returns the running job straight away; Get reports its progress. src
+// is closed once the import is done with it, including when the header is rejected.
+func (s *CustomerImportService) Start(src io.ReadCloser, filename string) (*model.ImportJob, error) {
+	job, reader, columns, err := s.begin(src, filename)
+	if err != nil {
+		src.Close()
+		return nil, err
+	}
+
+	started := *job // the background import keeps updating job
+	go func() {
+		defer src.Close()
+		if err := s.run(job, reader, columns); err != nil {
+			log.Println("⚠️ failed to finish import job", job.ID, ":", err)
+		}
+	}()
+	return &started, nil
+}
+
+// begin reads and checks the header row and creates the job
+func (s *CustomerImportService) begin(src io.Reader, filename string) (*model.ImportJob, *csv.Reader, []string, error) {
+	reader := csv.NewReader(src)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, nil, nil, appErrors.NewValidation("file", "is empty")
+		}
+		return nil, nil, nil, appErrors.NewValidation("file", fmt.Sprintf("unreadable header: %v", err))
+	}
+	columns, err := importHeader(header)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	reader.FieldsPerRecord = len(header)
+	reader.ReuseRecord = true
+
+	job := &model.ImportJob{Filename: filename}
+	if err := s.Jobs.Create(job); err != nil {
+		return nil, nil, nil, err
+	}
+	return job, reader, columns, nil
+}
+
+// run imports the rows after the header and stores the job's final status
+func (s *CustomerImportService) run(job *model.ImportJob, reader *csv.Reader, columns []string) error {
+	job.Status = "completed"
+	if err := s.importRows(job, reader, columns); err != nil {
+		job.Status = "failed"
+		job.Error = err.Error()
+	}
+	return s.Jobs.Finish(job)
+}
+
+// importHeader normalises and checks the column names of the header row
+func importHeader(header []string) ([]string, error) {
+	columns := make([]string, len(header))
+	seen := map[string]bool{}
+	for i, name := range header {
+		// spreadsheet exports often start with a UTF-8 byte order mark
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if lower := strings.ToLower(name); !strings.HasPrefix(lower, template.AttributePrefix) {
+			name = lower
+		} else {
+			name = template.AttributePrefix + name[len(template.AttributePrefix):]
+		}
+		if key, ok := strings.CutPrefix(name, template.AttributePrefix); ok {
+			if key == "" || strings.Contains(key, ".") {
+				return nil, appErrors.NewValidation("file", fmt.Sprintf("invalid attribute column %q", header[i]))
+			}
+		} else if !importColumns[name] {
+			return nil, appErrors.NewValidation("file", fmt.Sprintf("unknown column %q", header[i]))
+		}
+		if seen[name] {
+			return nil, appErrors.NewValidation("file", fmt.Sprintf("duplicate column %q", header[i]))
+		}
+		seen[name] = true
+		columns[i] = name
+	}
+	if !seen["phone"] {
+		return nil, appErrors.NewValidation("file", "a phone column is required")
+	}
+	return columns, nil
+}
+
+func (s *CustomerImportService) importRows(job *model.ImportJob, reader *csv.Reader, columns []string) error {
+	rows := make([]model.ImportRow, 0, importBatchSize)
+	customers := make([]*model.Customer, 0, importBatchSize)
+	flush := func() error {
+		if err := s.Jobs.SaveBatch(job.ID, rows, customers); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			switch row.Outcome {
+			case "accepted":
+				job.Accepted++
+			case "updated":
+				job.Updated++
+			default:
+				job.Rejected++
+			}
+		}
+		job.TotalRows += len(rows)
+		rows, customers = rows[:0], customers[:0]
+		return nil
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return flush()
+		}
+
+		row := model.ImportRow{RowNumber: job.TotalRows + len(rows) + 2} // the header is row 1
+		var customer *model.Customer
+
+		var parseErr *csv.ParseError
+		switch {
+		case errors.As(err, &parseErr):
+			row.Outcome, row.Reason = "rejected", parseErr.Err.Error()
+		case err != nil:
+			return err
+		default:
+			if customer, err = s.parseRecord(&row, columns, record); err != nil {
+				return err
+			}
+		}
+
+		rows = append(rows, row)
+		customers = append(customers, customer)
+		if len(rows) == importBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// parseRecord turns one CSV record into the customer to upsert. Invalid data rejects the
+// row and returns no customer; only unexpected failures are returned as errors.
+func (s *CustomerImportService) parseRecord(row *model.ImportRow, columns, record []string) (*model.Customer, error) {
+	c := &model.Customer{Attributes: map[string]string{}}
+	for i, column := range columns {
+		value := strings.TrimSpace(record[i])
+		switch column {
+		case "phone":
+			c.Phone = value
+		case "first_name":
+			c.FirstName = value
+		case "last_name":
+			c.LastName = value
+		case "location":
+			c.Location = value
+		case "preferred_product":
+			c.PreferredProduct = value
+		case "language":
+			c.Language = value
+		case "timezone":
+			c.Timezone = value
+		default:
+			if value != "" {
+				c.Attributes[strings.TrimPrefix(column, template.AttributePrefix)] = value
+			}
+		}
+	}
+
+	row.Phone = c.Phone
+	if err := validateCustomer(c, s.Phones); err != nil {
+		var validation *appErrors.ErrValidation
+		if errors.As(err, &validation) {
+			row.Outcome, row.Reason = "rejected", err.Error()
+			return nil, nil
+		}
+		return nil, err
+	}
+	row.Phone = c.Phone
+	return c, nil
+}
+
+// Get fetches an import job's summary
+func (s *CustomerImportService) Get(id int) (*model.ImportJob, error) {
+	return s.Jobs.GetByID(id)
+}
+
+// Rows returns a page of an import job's report, optionally only rows with one outcome
+func (s *CustomerImportService) Rows(jobID int, outcome string, page, pageSize int) ([]model.ImportRow, map[string]int, error) {
+	if outcome != "" && !importOutcomes[outcome] {
+		return nil, nil, appErrors.NewValidation("outcome", "must be accepted, updated or rejected")
+	}
+	if _, err := s.Jobs.GetByID(jobID); err != nil {
+		return nil, nil, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 100
+	}
+	if pageSize > 1000 {
+		pageSize = 1000
+	}
+
+	rows, total, err := s.Jobs.ListRows(jobID, outcome, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	pagination := map[string]int{
+		"page":        page,
+		"page_size":   pageSize,
+		"total_count": total,
+		"total_pages": (total + pageSize - 1) / pageSize,
+	}
+	return rows, pagination, nil
+}
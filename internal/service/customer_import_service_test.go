@@ -0,0 +1,212 @@
+package service_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+// UpsertByPhone merges into the customer with the same phone, like the SQL upsert
+func (m *MockCustomerStore) UpsertByPhone(c *model.Customer) (bool, error) {
+	for _, existing := range m.customers {
+		if existing.Phone != c.Phone {
+			continue
+		}
+		if c.FirstName != "" {
+			existing.FirstName = c.FirstName
+		}
+		if c.Location != "" {
+			existing.Location = c.Location
+		}
+		for k, v := range c.Attributes {
+			existing.Attributes[k] = v
+		}
+		c.ID = existing.ID
+		return false, nil
+	}
+	return true, m.Create(c)
+}
+
+// MockImportJobRepo keeps jobs and their rows in memory and upserts imported customers
+// into a MockCustomerStore
+type MockImportJobRepo struct {
+	customers *MockCustomerStore
+	jobs      map[int]*model.ImportJob
+	rows      map[int][]model.ImportRow
+	batches   int
+	finished  chan int // receives the ID of each finished job
+}
+
+func NewMockImportJobRepo(customers *MockCustomerStore) *MockImportJobRepo {
+	return &MockImportJobRepo{
+		customers: customers,
+		jobs:      map[int]*model.ImportJob{},
+		rows:      map[int][]model.ImportRow{},
+		finished:  make(chan int, 10),
+	}
+}
+
+func (m *MockImportJobRepo) Create(job *model.ImportJob) error {
+	job.ID = len(m.jobs) + 1
+	job.Status = "running"
+	m.jobs[job.ID] = job
+	return nil
+}
+
+func (m *MockImportJobRepo) Finish(job *model.ImportJob) error {
+	m.finished <- job.ID
+	return nil
+}
+
+func (m *MockImportJobRepo) SaveBatch(jobID int, rows []model.ImportRow, customers []*model.Customer) error {
+	m.batches++
+	for i, row := range rows {
+		if c := customers[i]; c != nil {
+			created, err := m.customers.UpsertByPhone(c)
+			if err != nil {
+				return err
+			}
+			row.CustomerID = &c.ID
+			row.Outcome = "updated"
+			if created {
+				row.Outcome = "accepted"
+			}
+			rows[i] = row
+		}
+		m.rows[jobID] = append(m.rows[jobID], row)
+	}
+	return nil
+}
+
+func (m *MockImportJobRepo) GetByID(id int) (*model.ImportJob, error) {
+	if job, ok := m.jobs[id]; ok {
+		return job, nil
+	}
+	return nil, appErrors.NewImportJobNotFound(id)
+}
+
+func (m *MockImportJobRepo) ListRows(jobID int, outcome string, offset, limit int) ([]model.ImportRow, int, error) {
+	rows := []model.ImportRow{}
+	for _, row := range m.rows[jobID] {
+		if outcome == "" || row.Outcome == outcome {
+			rows = append(rows, row)
+		}
+	}
+	return rows, len(rows), nil
+}
+
+func TestCustomerImport(t *testing.T) {
+	customers := NewMockCustomerStore()
+	customers.Create(&model.Customer{Phone: "+254710000001", FirstName: "Alice", Location: "Nairobi", Attributes: map[string]string{}})
+	svc := &service.CustomerImportService{Jobs: NewMockImportJobRepo(customers)}
+
+	csv := "\ufeffPhone,first_name,location,attr.LoyaltyTier\n" +
+		"0710 000-001,,Mombasa,gold\n" + // existing customer, blank name kept
+		"0710000002,Bob,Kisumu,\n" +
+		"call me,Carol,Nakuru,\n" +
+		",Dan,Eldoret,\n" +
+		"0710000005,Eve\n"
+
+	job, err := svc.Import(strings.NewReader(csv), "customers.csv")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.Status != "completed" || job.TotalRows != 5 || job.Accepted != 1 || job.Updated != 1 || job.Rejected != 3 {
+		t.Fatalf("unexpected job summary %+v", job)
+	}
+
+	alice, _ := customers.GetByID(1)
+	if alice.FirstName != "Alice" || alice.Location != "Mombasa" || alice.Attributes["LoyaltyTier"] != "gold" {
+		t.Errorf("expected existing customer to be merged, got %+v", alice)
+	}
+
+	rejected, _, err := svc.Rows(job.ID, "rejected", 1, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantRows := []int{4, 5, 6}
+	if len(rejected) != len(wantRows) {
+		t.Fatalf("expected %d rejected rows, got %+v", len(wantRows), rejected)
+	}
+	for i, row := range rejected {
+		if row.RowNumber != wantRows[i] || row.Reason == "" {
+			t.Errorf("rejected row %d: unexpected %+v", i, row)
+		}
+	}
+}
+
+func TestCustomerImportRejectsBadHeader(t *testing.T) {
+	svc := &service.CustomerImportService{Jobs: NewMockImportJobRepo(NewMockCustomerStore())}
+
+	for _, csv := range []string{"", "first_name,location\n", "phone,email\n", "phone,phone\n"} {
+		_, err := svc.Import(strings.NewReader(csv), "bad.csv")
+		var validation *appErrors.ErrValidation
+		if !errors.As(err, &validation) || validation.Field != "file" {
+			t.Errorf("%q: expected header to be rejected, got %v", csv, err)
+		}
+	}
+}
+
+// closeRecorder signals when the import closes its source
+type closeRecorder struct {
+	io.Reader
+	closed chan bool
+}
+
+func newCloseRecorder(csv string) *closeRecorder {
+	return &closeRecorder{Reader: strings.NewReader(csv), closed: make(chan bool, 1)}
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed <- true
+	return nil
+}
+
+func TestCustomerImportStartRunsInBackground(t *testing.T) {
+	customers := NewMockCustomerStore()
+	jobs := NewMockImportJobRepo(customers)
+	svc := &service.CustomerImportService{Jobs: jobs}
+
+	var csv strings.Builder
+	csv.WriteString("phone,first_name\n")
+	for i := 0; i < 1200; i++ {
+		fmt.Fprintf(&csv, "07%08d,Customer %d\n", i, i)
+	}
+	src := newCloseRecorder(csv.String())
+
+	started, err := svc.Start(src, "big.csv")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if started.ID == 0 || started.Status != "running" || started.TotalRows != 0 {
+		t.Fatalf("expected the running job to be returned before any rows, got %+v", started)
+	}
+
+	if id := <-jobs.finished; id != started.ID {
+		t.Fatalf("expected job %d to finish, got %d", started.ID, id)
+	}
+	job, _ := svc.Get(started.ID)
+	if job.Status != "completed" || job.TotalRows != 1200 || job.Accepted != 1200 {
+		t.Errorf("unexpected job summary %+v", job)
+	}
+	if jobs.batches != 3 {
+		t.Errorf("expected rows to be saved in 3 batches, got %d", jobs.batches)
+	}
+	<-src.closed // the upload is closed once imported
+
+	bad := newCloseRecorder("phone,email\n")
+	if _, err := svc.Start(bad, "bad.csv"); err == nil {
+		t.Error("expected a bad header to be rejected")
+	}
+	select {
+	case <-bad.closed:
+	default:
+		t.Error("expected a rejected upload to be closed")
+	}
+}
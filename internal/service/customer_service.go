@@ -1 +1,186 @@
+// internal/service/customer_service.go
 package service
+
+import (
+	"strings"
+	"time"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/phone"
+	"github.com/unclebandit/smsleopard-backend/internal/repository"
+)
+
+// CustomerService manages the customer directory
+type CustomerService struct {
+	Repo repository.CustomerRepositoryInterface
+
+	// Phones converts phones to E.164 on every write; nil uses phone.Default
+	Phones *phone.Normalizer
+}
+
+// CustomerPatch holds the fields PATCH /customers/{id} may change; nil fields are left
+// untouched. Attributes are merged and a key with an empty value is removed.
+type CustomerPatch struct {
+	Phone            *string           `json:"phone"`
+	FirstName        *string           `json:"first_name"`
+	LastName         *string           `json:"last_name"`
+	Location         *string           `json:"location"`
+	PreferredProduct *string           `json:"preferred_product"`
+	Language         *string           `json:"language"`
+	Timezone         *string           `json:"timezone"`
+	Attributes       map[string]string `json:"attributes"`
+}
+
+// Create validates and stores a new customer
+func (s *CustomerService) Create(c *model.Customer) error {
+	if err := validateCustomer(c, s.Phones); err != nil {
+		return err
+	}
+	if c.Attributes == nil {
+		c.Attributes = map[string]string{}
+	}
+	return s.Repo.Create(c)
+}
+
+// Get fetches a customer, returning ErrCustomerNotFound when it does not exist
+func (s *CustomerService) Get(id int) (*model.Customer, error) {
+	c, err := s.Repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, appErrors.NewCustomerNotFound(id)
+	}
+	return c, nil
+}
+
+// Update applies a partial update and re-validates the customer
+func (s *CustomerService) Update(id int, patch CustomerPatch) (*model.Customer, error) {
+	c, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if c.ErasedAt != nil {
+		return nil, appErrors.NewConflict("id", "customer has been erased")
+	}
+
+	if patch.Phone != nil {
+		c.Phone = *patch.Phone
+	}
+	if patch.FirstName != nil {
+		c.FirstName = *patch.FirstName
+	}
+	if patch.LastName != nil {
+		c.LastName = *patch.LastName
+	}
+	if patch.Location != nil {
+		c.Location = *patch.Location
+	}
+	if patch.PreferredProduct != nil {
+		c.PreferredProduct = *patch.PreferredProduct
+	}
+	if patch.Language != nil {
+		c.Language = *patch.Language
+	}
+	if patch.Timezone != nil {
+		c.Timezone = *patch.Timezone
+	}
+	if c.Attributes == nil {
+		c.Attributes = map[string]string{}
+	}
+	for k, v := range patch.Attributes {
+		if v == "" {
+			delete(c.Attributes, k)
+			continue
+		}
+		c.Attributes[k] = v
+	}
+
+	if err := validateCustomer(c, s.Phones); err != nil {
+		return nil, err
+	}
+	if err := s.Repo.Update(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Delete removes a customer
+func (s *CustomerService) Delete(id int) error {
+	return s.Repo.Delete(id)
+}
+
+// CustomerExport is everything stored about a customer, returned for data subject requests
+type CustomerExport struct {
+	Customer *model.Customer         `json:"customer"`
+	Messages []model.OutboundMessage `json:"messages"`
+}
+
+// Export gathers a customer's record and every message they were sent
+func (s *CustomerService) Export(id int) (*CustomerExport, error) {
+	c, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := s.Repo.Messages(id)
+	if err != nil {
+		return nil, err
+	}
+	return &CustomerExport{Customer: c, Messages: messages}, nil
+}
+
+// Erase anonymizes a customer who asked for their data to be removed. Unlike Delete it
+// works for customers who were already messaged, keeping campaign stats intact.
+func (s *CustomerService) Erase(id int) error {
+	return s.Repo.Erase(id)
+}
+
+// List returns a page of customers filtered by location and preferred product
+func (s *CustomerService) List(page, pageSize int, location, product string) ([]model.Customer, map[string]int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	customers, total, err := s.Repo.List((page-1)*pageSize, pageSize, location, product)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := map[string]int{
+		"page":        page,
+		"page_size":   pageSize,
+		"total_count": total,
+		"total_pages": (total + pageSize - 1) / pageSize,
+	}
+	return customers, pagination, nil
+}
+
+// validateCustomer checks a customer before it is stored, converting its phone to E.164
+func validateCustomer(c *model.Customer, phones *phone.Normalizer) error {
+	if phones == nil {
+		phones = phone.Default
+	}
+	normalized, err := phones.Normalize(c.Phone)
+	if err != nil {
+		return appErrors.NewValidation("phone", err.Error())
+	}
+	c.Phone = normalized
+	if c.Timezone != "" {
+		if _, err := time.LoadLocation(c.Timezone); err != nil {
+			return appErrors.NewValidation("timezone", "must be an IANA timezone such as Africa/Nairobi")
+		}
+	}
+	for key := range c.Attributes {
+		if key == "" || strings.Contains(key, ".") {
+			return appErrors.NewValidation("attributes", "keys must be non-empty and cannot contain '.'")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+// MockCustomerStore keeps customers in memory and enforces unique phones
+type MockCustomerStore struct {
+	MockCustomerRepo
+	customers map[int]*model.Customer
+	nextID    int
+}
+
+func NewMockCustomerStore() *MockCustomerStore {
+	return &MockCustomerStore{customers: map[int]*model.Customer{}}
+}
+
+func (m *MockCustomerStore) GetByID(id int) (*model.Customer, error) {
+	if c, ok := m.customers[id]; ok {
+		stored := *c
+		return &stored, nil
+	}
+	return nil, nil
+}
+
+func (m *MockCustomerStore) ListAll() ([]model.Customer, error) {
+	customers := []model.Customer{}
+	for _, c := range m.customers {
+		customers = append(customers, *c)
+	}
+	return customers, nil
+}
+
+func (m *MockCustomerStore) Messages(customerID int) ([]model.OutboundMessage, error) {
+	return []model.OutboundMessage{{ID: 1, CustomerID: customerID, Status: "sent", RenderedContent: "Hi"}}, nil
+}
+
+func (m *MockCustomerStore) Erase(id int) error {
+	if _, ok := m.customers[id]; !ok {
+		return appErrors.NewCustomerNotFound(id)
+	}
+	now := time.Now()
+	m.customers[id] = &model.Customer{ID: id, Phone: "erased", Attributes: map[string]string{}, ErasedAt: &now}
+	return nil
+}
+
+func (m *MockCustomerStore) phoneTaken(phone string, id int) bool {
+	for _, c := range m.customers {
+		if c.Phone == phone && c.ID != id {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MockCustomerStore) Create(c *model.Customer) error {
+	if m.phoneTaken(c.Phone, 0) {
+		return appErrors.NewConflict("phone", "is already used by another customer")
+	}
+	m.nextID++
+	c.ID = m.nextID
+	stored := *c
+	m.customers[c.ID] = &stored
+	return nil
+}
+
+func (m *MockCustomerStore) Update(c *model.Customer) error {
+	if m.phoneTaken(c.Phone, c.ID) {
+		return appErrors.NewConflict("phone", "is already used by another customer")
+	}
+	stored := *c
+	m.customers[c.ID] = &stored
+	return nil
+}
+
+func (m *MockCustomerStore) Delete(id int) error {
+	if _, ok := m.customers[id]; !ok {
+		return appErrors.NewCustomerNotFound(id)
+	}
+	delete(m.customers, id)
+	return nil
+}
+
+func TestCustomerCRUD(t *testing.T) {
+	store := NewMockCustomerStore()
+	svc := &service.CustomerService{Repo: store}
+
+	alice := &model.Customer{Phone: "0710000001", FirstName: "Alice", Location: "Nairobi"}
+	if err := svc.Create(alice); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if alice.Phone != "+254710000001" {
+		t.Errorf("expected phone stored as E.164, got %q", alice.Phone)
+	}
+
+	var conflict *appErrors.ErrConflict
+	if err := svc.Create(&model.Customer{Phone: "+254 710 000 001"}); !errors.As(err, &conflict) || conflict.Field != "phone" {
+		t.Fatalf("expected duplicate phone conflict, got %v", err)
+	}
+
+	var validation *appErrors.ErrValidation
+	if err := svc.Create(&model.Customer{Phone: "not a phone"}); !errors.As(err, &validation) {
+		t.Fatalf("expected invalid phone to be rejected, got %v", err)
+	}
+	if err := svc.Create(&model.Customer{Phone: "0710000009", Timezone: "Mars/Olympus"}); !errors.As(err, &validation) || validation.Field != "timezone" {
+		t.Fatalf("expected unknown timezone to be rejected, got %v", err)
+	}
+
+	location := "Mombasa"
+	updated, err := svc.Update(alice.ID, service.CustomerPatch{
+		Location:   &location,
+		Attributes: map[string]string{"loyalty_tier": "gold"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Location != "Mombasa" || updated.FirstName != "Alice" || updated.Attributes["loyalty_tier"] != "gold" {
+		t.Errorf("unexpected update result %+v", updated)
+	}
+
+	if err := svc.Delete(alice.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var notFound *appErrors.ErrCustomerNotFound
+	if _, err := svc.Get(alice.ID); !errors.As(err, &notFound) {
+		t.Errorf("expected deleted customer to be gone, got %v", err)
+	}
+}
+
+func TestNormalizePhonesBackfill(t *testing.T) {
+	store := NewMockCustomerStore()
+	for _, p := range []string{"0710000001", "+254710000002", "0710 000 003", "254710000003", "12345"} {
+		store.Create(&model.Customer{Phone: p})
+	}
+	svc := &service.CustomerService{Repo: store}
+
+	report, err := svc.NormalizePhones(true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Checked != 5 || report.Updated != 1 || len(report.Invalid) != 1 || len(report.Duplicates) != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if dup := report.Duplicates[0]; dup.Phone != "+254710000003" || len(dup.CustomerIDs) != 2 {
+		t.Errorf("unexpected duplicate %+v", dup)
+	}
+	if c, _ := store.GetByID(1); c.Phone != "0710000001" {
+		t.Errorf("expected dry run to leave phones alone, got %q", c.Phone)
+	}
+
+	if _, err := svc.NormalizePhones(false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c, _ := store.GetByID(1); c.Phone != "+254710000001" {
+		t.Errorf("expected phone to be normalized, got %q", c.Phone)
+	}
+	if c, _ := store.GetByID(3); c.Phone != "0710 000 003" {
+		t.Errorf("expected duplicate to be left for a manual merge, got %q", c.Phone)
+	}
+}
+
+func TestCustomerExportAndErase(t *testing.T) {
+	store := NewMockCustomerStore()
+	svc := &service.CustomerService{Repo: store}
+
+	alice := &model.Customer{Phone: "0710000001", FirstName: "Alice"}
+	svc.Create(alice)
+
+	export, err := svc.Export(alice.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if export.Customer.FirstName != "Alice" || len(export.Messages) != 1 {
+		t.Errorf("unexpected export %+v", export)
+	}
+
+	if err := svc.Erase(alice.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	erased, _ := svc.Get(alice.ID)
+	if erased.FirstName != "" || erased.ErasedAt == nil {
+		t.Errorf("expected customer to be anonymized, got %+v", erased)
+	}
+
+	name := "Alice"
+	var conflict *appErrors.ErrConflict
+	if _, err := svc.Update(alice.ID, service.CustomerPatch{FirstName: &name}); !errors.As(err, &conflict) {
+		t.Errorf("expected erased customer to be read-only, got %v", err)
+	}
+
+	var notFound *appErrors.ErrCustomerNotFound
+	if _, err := svc.Export(42); !errors.As(err, &notFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+func TestErasedCustomerMessagesAreNotDelivered(t *testing.T) {
+	store := NewMockCustomerStore()
+	alice := &model.Customer{Phone: "0710000001", FirstName: "Alice"}
+	store.Create(alice)
+	if err := store.Erase(alice.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, status := range []string{"deferred", "held", "paused", "retrying"} {
+		repo := &MockDispatchRepo{statuses: map[int]string{}}
+		sent := 0
+		dispatch := &service.DispatchService{
+			CampaignRepo: repo,
+			CustomerRepo: store,
+			Send:         func(msg *model.OutboundMessage) error { sent++; return nil },
+		}
+
+		// ReleaseDue or ResumeCampaign queues a waiting message again as pending, and a
+		// retrying one is delivered as it is
+		repo.statuses[alice.ID] = "pending"
+		if status == "retrying" {
+			repo.statuses[alice.ID] = status
+		}
+		if err := dispatch.Deliver(alice.ID); err != nil {
+			t.Fatalf("%s: unexpected error: %v", status, err)
+		}
+		if sent != 0 || repo.statuses[alice.ID] != "suppressed" {
+			t.Errorf("%s: expected message to an erased customer to be suppressed, got %q after %d sends", status, repo.statuses[alice.ID], sent)
+		}
+	}
+}
+
+func TestSendCampaignSkipsErasedCustomers(t *testing.T) {
+	store := NewMockCustomerStore()
+	alice := &model.Customer{Phone: "0710000001", FirstName: "Alice"}
+	bob := &model.Customer{Phone: "0710000002", FirstName: "Bob"}
+	store.Create(alice)
+	store.Create(bob)
+	store.Erase(alice.ID)
+
+	q := &MockQueue{}
+	svc := &service.CampaignService{CampaignRepo: &MockDispatchRepo{statuses: map[int]string{}}, CustomerRepo: store, Queue: q}
+	result, err := svc.SendCampaign(1, []int{alice.ID, bob.ID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.MessagesQueued != 1 || len(q.published) != 1 {
+		t.Errorf("expected only the customer who was not erased to be queued, got %d", result.MessagesQueued)
+	}
+}
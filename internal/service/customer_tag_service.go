@@ -0,0 +1,101 @@
+// internal/service/customer_tag_service.go
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/tagexpr"
+)
+
+// normalizeTags lowercases, trims and de-duplicates tags, rejecting malformed ones
+func normalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, appErrors.NewValidation("tags", "at least one tag is required")
+	}
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !tagexpr.ValidTag(tag) {
+			return nil, appErrors.NewValidation("tags", fmt.Sprintf("%q must be 1-50 letters, digits, - or _", tag))
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized, nil
+}
+
+// AddTags labels a customer and returns all of the customer's tags
+func (s *CustomerService) AddTags(customerID int, tags []string) ([]string, error) {
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	c, err := s.Get(customerID)
+	if err != nil {
+		return nil, err
+	}
+	if c.ErasedAt != nil {
+		return nil, appErrors.NewConflict("id", "customer has been erased")
+	}
+	if err := s.Repo.AddTags(customerID, tags); err != nil {
+		return nil, err
+	}
+	return s.Repo.Tags(customerID)
+}
+
+// RemoveTag takes a label off a customer, returning ErrTagNotFound when it was not there
+func (s *CustomerService) RemoveTag(customerID int, tag string) error {
+	if _, err := s.Get(customerID); err != nil {
+		return err
+	}
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	removed, err := s.Repo.RemoveTag(customerID, tag)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return appErrors.NewTagNotFound(customerID, tag)
+	}
+	return nil
+}
+
+// Tags lists a customer's tags
+func (s *CustomerService) Tags(customerID int) ([]string, error) {
+	if _, err := s.Get(customerID); err != nil {
+		return nil, err
+	}
+	return s.Repo.Tags(customerID)
+}
+
+// ParseTagExpression parses a tag expression, reporting syntax errors as validation errors
+func ParseTagExpression(expr string) (tagexpr.Expr, error) {
+	e, err := tagexpr.Parse(expr)
+	if err != nil {
+		var syntax *tagexpr.Error
+		if errors.As(err, &syntax) {
+			return nil, appErrors.NewValidation("tag_expression", syntax.Error())
+		}
+		return nil, err
+	}
+	return e, nil
+}
+
+// SendCampaignToTags sends the campaign to every customer whose tags match the expression
+// at the time of sending, e.g. `vip AND NOT churn-risk`
+func (s *CampaignService) SendCampaignToTags(campaignID int, expr string) (*SendCampaignResult, error) {
+	e, err := ParseTagExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+	customerIDs, err := s.CustomerRepo.CustomerIDsByTags(e)
+	if err != nil {
+		return nil, err
+	}
+	return s.SendCampaign(campaignID, customerIDs)
+}
@@ -0,0 +1,113 @@
+package service_test
+
+import (
+	"errors"
+	"sort"
+	"testing"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+	"github.com/unclebandit/smsleopard-backend/internal/tagexpr"
+)
+
+// MockTaggedCustomerRepo keeps tags in memory and evaluates expressions the way the SQL does
+type MockTaggedCustomerRepo struct {
+	MockCustomerRepo
+	tags map[int]map[string]bool
+}
+
+func newMockTaggedCustomerRepo() *MockTaggedCustomerRepo {
+	return &MockTaggedCustomerRepo{tags: map[int]map[string]bool{1: {}, 2: {}}}
+}
+
+func (m *MockTaggedCustomerRepo) AddTags(customerID int, tags []string) error {
+	for _, tag := range tags {
+		m.tags[customerID][tag] = true
+	}
+	return nil
+}
+
+func (m *MockTaggedCustomerRepo) RemoveTag(customerID int, tag string) (bool, error) {
+	had := m.tags[customerID][tag]
+	delete(m.tags[customerID], tag)
+	return had, nil
+}
+
+func (m *MockTaggedCustomerRepo) Tags(customerID int) ([]string, error) {
+	tags := []string{}
+	for tag := range m.tags[customerID] {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+func (m *MockTaggedCustomerRepo) CustomerIDsByTags(expr tagexpr.Expr) ([]int, error) {
+	ids := []int{}
+	for _, id := range []int{1, 2} {
+		if expr.Match(m.tags[id]) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func TestCustomerTags(t *testing.T) {
+	repo := newMockTaggedCustomerRepo()
+	svc := &service.CustomerService{Repo: repo}
+
+	tags, err := svc.AddTags(1, []string{" VIP ", "beta", "vip"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tags) != 2 || tags[0] != "beta" || tags[1] != "vip" {
+		t.Errorf("expected [beta vip], got %v", tags)
+	}
+
+	var validation *appErrors.ErrValidation
+	if _, err := svc.AddTags(1, []string{"not a tag"}); !errors.As(err, &validation) || validation.Field != "tags" {
+		t.Errorf("expected malformed tag to be rejected, got %v", err)
+	}
+	if _, err := svc.AddTags(1, nil); !errors.As(err, &validation) {
+		t.Errorf("expected empty tag list to be rejected, got %v", err)
+	}
+
+	var customerNotFound *appErrors.ErrCustomerNotFound
+	if _, err := svc.AddTags(42, []string{"vip"}); !errors.As(err, &customerNotFound) {
+		t.Errorf("expected customer not found, got %v", err)
+	}
+
+	if err := svc.RemoveTag(1, "beta"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var tagNotFound *appErrors.ErrTagNotFound
+	if err := svc.RemoveTag(1, "beta"); !errors.As(err, &tagNotFound) {
+		t.Errorf("expected tag not found, got %v", err)
+	}
+}
+
+func TestSendCampaignToTags(t *testing.T) {
+	repo := newMockTaggedCustomerRepo()
+	repo.AddTags(1, []string{"vip"})
+	repo.AddTags(2, []string{"vip", "churn-risk"})
+
+	q := &MockQueue{}
+	svc := &service.CampaignService{
+		CampaignRepo: &MockSendRepo{},
+		CustomerRepo: repo,
+		Queue:        q,
+	}
+
+	result, err := svc.SendCampaignToTags(1, "vip AND NOT churn-risk")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.MessagesQueued != 1 || len(q.published) != 1 {
+		t.Errorf("expected 1 message queued, got %d (published %d)", result.MessagesQueued, len(q.published))
+	}
+
+	var validation *appErrors.ErrValidation
+	if _, err := svc.SendCampaignToTags(1, "vip AND"); !errors.As(err, &validation) || validation.Field != "tag_expression" {
+		t.Errorf("expected syntax error as validation error, got %v", err)
+	}
+}
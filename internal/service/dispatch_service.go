@@ -0,0 +1,178 @@
+// internal/service/dispatch_service.go
+package service
+
+import (
+	"log"
+	"time"
+
+	"github.com/unclebandit/smsleopard-backend/internal/campaignstate"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/queue"
+	"github.com/unclebandit/smsleopard-backend/internal/repository"
+)
+
+// DispatchService delivers queued outbound messages
+type DispatchService struct {
+	CampaignRepo    repository.CampaignRepositoryInterface
+	SuppressionRepo repository.SuppressionRepositoryInterface // nil disables the check
+	FrequencyCap    *FrequencyCap                             // nil disables capping
+	QuietHours      *QuietHoursPolicy                         // nil delivers at any hour
+	CustomerRepo    repository.CustomerRepositoryInterface    // looks up customer timezones
+	Completion      *Completion                               // nil leaves campaigns sending
+
+	// Send hands a rendered message to the provider
+	Send func(msg *model.OutboundMessage) error
+
+	// Queue receives deferred and held messages again once they are due
+	Queue queue.Queue
+}
+
+// Deliver claims one outbound message, sends it and records the outcome, unless Screen holds
+// it back. A returned error asks the queue to retry.
+func (d *DispatchService) Deliver(msgID int) error {
+	msg, err := d.CampaignRepo.GetOutboundMessageByID(msgID)
+	if err != nil {
+		return err
+	}
+	if msg == nil {
+		log.Println("⚠️ Message not found for ID:", msgID)
+		return nil // no retry
+	}
+	if msg.Status != "pending" && msg.Status != "retrying" {
+		return nil // already handled or held back, e.g. a redelivered job
+	}
+	// a message queued twice, e.g. by a resumed dispatch, is only delivered by whoever claims it
+	if claimed, err := d.CampaignRepo.ClaimOutboundMessage(msgID); err != nil || !claimed {
+		return err
+	}
+
+	campaign, err := d.CampaignRepo.GetByID(msg.CampaignID)
+	if err != nil {
+		return err
+	}
+	if held, err := d.Screen(msg, campaign); err != nil || held {
+		if err == nil {
+			d.CheckCompletion(msg.CampaignID)
+		}
+		return err
+	}
+
+	if err := d.Send(msg); err != nil {
+		log.Println("⚠️ Failed to send message:", err)
+		status := FailureStatus(msg.RetryCount)
+		_ = d.CampaignRepo.UpdateOutboundMessageStatus(msgID, status, err.Error())
+		if status == "retrying" {
+			return err
+		}
+		d.CheckCompletion(msg.CampaignID)
+		return nil // out of retries
+	}
+	if err := d.CampaignRepo.UpdateOutboundMessageStatus(msgID, "sent", ""); err != nil {
+		return err
+	}
+	d.CheckCompletion(msg.CampaignID)
+	return nil
+}
+
+// CheckCompletion finalizes the campaign once its last unfinished message is handled. Errors are
+// only logged, since the message itself was handled.
+func (d *DispatchService) CheckCompletion(campaignID int) {
+	if _, err := d.Completion.Check(d.CampaignRepo, campaignID); err != nil {
+		log.Println("⚠️ failed to check completion of campaign", campaignID, ":", err)
+	}
+}
+
+// Screen runs the checks made right before sending: the campaign's status, whether the customer
+// was erased, the suppression list, quiet hours, then the frequency cap. It reports whether msg was held back, in which
+// case its new status is already stored.
+func (d *DispatchService) Screen(msg *model.OutboundMessage, campaign *model.Campaign) (bool, error) {
+	switch campaign.Status {
+	case campaignstate.Paused:
+		// ResumeCampaign queues paused messages again
+		log.Println("⏸️ Campaign", campaign.ID, "is paused, holding message", msg.ID)
+		msg.Status = "paused"
+		return true, d.CampaignRepo.UpdateOutboundMessageStatus(msg.ID, "paused", "campaign paused")
+	case campaignstate.Cancelled:
+		log.Println("🛑 Campaign", campaign.ID, "was cancelled, dropping message", msg.ID)
+		msg.Status = "cancelled"
+		return true, d.CampaignRepo.UpdateOutboundMessageStatus(msg.ID, "cancelled", "campaign cancelled")
+	}
+
+	var customer *model.Customer
+	if d.CustomerRepo != nil {
+		var err error
+		if customer, err = d.CustomerRepo.GetByID(msg.CustomerID); err != nil {
+			return false, err
+		}
+	}
+	if customer != nil && customer.ErasedAt != nil {
+		// erasing suppresses waiting messages; this catches any queued before it
+		log.Println("🚫 Customer", msg.CustomerID, "was erased, skipping message", msg.ID)
+		msg.Status = "suppressed"
+		return true, d.CampaignRepo.UpdateOutboundMessageStatus(msg.ID, "suppressed", "customer erased")
+	}
+
+	if suppressed, err := d.Suppress(msg, campaign.Channel); err != nil || suppressed {
+		return suppressed, err
+	}
+
+	if d.QuietHours != nil {
+		timezone := ""
+		if customer != nil {
+			timezone = customer.Timezone
+		}
+		if until, quiet := d.QuietHours.Until(campaign, timezone, time.Now()); quiet {
+			log.Println("🌙 Quiet hours for customer", msg.CustomerID, ", holding message", msg.ID, "until", until)
+			msg.Status, msg.NotBefore = "held", &until
+			return true, d.CampaignRepo.HoldOutboundMessage(msg.ID, "held", until, "quiet hours")
+		}
+	}
+
+	// only sent messages count here, so queued messages do not hold each other back
+	capped, err := d.FrequencyCap.Capped(d.CampaignRepo, campaign, []int{msg.CustomerID}, false)
+	if err != nil {
+		return false, err
+	}
+	if until, ok := capped[msg.CustomerID]; ok {
+		log.Println("⏳ Customer", msg.CustomerID, "reached the frequency cap, holding message", msg.ID)
+		return true, applyFrequencyCap(d.CampaignRepo, campaign, msg, until)
+	}
+	return false, nil
+}
+
+// ReleaseDue queues deferred and held messages whose wait is over and returns how many it released
+func (d *DispatchService) ReleaseDue() (int, error) {
+	ids, err := d.CampaignRepo.ReleaseDueMessages(time.Now())
+	if err != nil {
+		return 0, err
+	}
+	for i, id := range ids {
+		if err := d.Queue.Publish("campaign_sends", id); err != nil {
+			return i, err
+		}
+	}
+	return len(ids), nil
+}
+
+// Suppress marks msg suppressed when its customer is on the suppression list for channel,
+// and reports whether it did
+func (d *DispatchService) Suppress(msg *model.OutboundMessage, channel string) (bool, error) {
+	if d.SuppressionRepo == nil {
+		return false, nil
+	}
+	suppressed, err := d.SuppressionRepo.Suppressed([]int{msg.CustomerID}, channel)
+	if err != nil {
+		return false, err
+	}
+	reason, ok := suppressed[msg.CustomerID]
+	if !ok {
+		return false, nil
+	}
+
+	log.Println("🚫 Customer", msg.CustomerID, "is suppressed, skipping message", msg.ID)
+	if err := d.CampaignRepo.UpdateOutboundMessageStatus(msg.ID, "suppressed", reason); err != nil {
+		return false, err
+	}
+	msg.Status = "suppressed"
+	return true, nil
+}
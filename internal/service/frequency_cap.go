@@ -0,0 +1,71 @@
+// internal/service/frequency_cap.go
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/repository"
+)
+
+// frequencyCapPolicies are what a campaign may do with customers over the cap
+var frequencyCapPolicies = map[string]bool{"defer": true, "skip": true}
+
+// FrequencyCap limits how many messages a customer receives on one channel within Window,
+// across all campaigns
+type FrequencyCap struct {
+	Max    int
+	Window time.Duration
+}
+
+// ParseFrequencyCap reads a cap from configuration values such as "2" and "24h". An empty
+// max disables capping; the window defaults to 24 hours.
+func ParseFrequencyCap(max, window string) (*FrequencyCap, error) {
+	if max == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(max)
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("frequency cap must be a positive number, got %q", max)
+	}
+	f := &FrequencyCap{Max: n, Window: 24 * time.Hour}
+	if window != "" {
+		if f.Window, err = time.ParseDuration(window); err != nil || f.Window <= 0 {
+			return nil, fmt.Errorf("frequency cap window must be a positive duration, got %q", window)
+		}
+	}
+	return f, nil
+}
+
+// Capped returns the customers who already reached the cap for campaign's channel, mapped to
+// when their oldest counted message leaves the window. Messages of the campaign itself are
+// not counted; withPending counts queued messages of other campaigns as well as sent ones.
+func (f *FrequencyCap) Capped(repo repository.CampaignRepositoryInterface, campaign *model.Campaign, customerIDs []int, withPending bool) (map[int]time.Time, error) {
+	capped := map[int]time.Time{}
+	if f == nil {
+		return capped, nil
+	}
+	recent, err := repo.RecentSends(customerIDs, campaign.Channel, time.Now().Add(-f.Window), campaign.ID, withPending)
+	if err != nil {
+		return nil, err
+	}
+	for id, sends := range recent {
+		if sends.Count >= f.Max {
+			capped[id] = sends.Oldest.Add(f.Window)
+		}
+	}
+	return capped, nil
+}
+
+// applyFrequencyCap defers msg until the cap frees up, or skips it, according to the campaign's policy
+func applyFrequencyCap(repo repository.CampaignRepositoryInterface, campaign *model.Campaign, msg *model.OutboundMessage, until time.Time) error {
+	if campaign.FrequencyCapPolicy == "skip" {
+		msg.Status = "skipped"
+		return repo.UpdateOutboundMessageStatus(msg.ID, "skipped", "frequency cap reached")
+	}
+	msg.Status = "deferred"
+	msg.NotBefore = &until
+	return repo.HoldOutboundMessage(msg.ID, "deferred", until, "frequency cap reached")
+}
@@ -0,0 +1,144 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/repository"
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+// MockCapRepo reports fixed recent sends and records deferrals
+type MockCapRepo struct {
+	MockDispatchRepo
+	policy   string
+	recent   map[int]repository.RecentSends
+	deferred map[int]time.Time
+	due      []int
+}
+
+func newMockCapRepo(policy string) *MockCapRepo {
+	return &MockCapRepo{
+		MockDispatchRepo: MockDispatchRepo{statuses: map[int]string{}},
+		policy:           policy,
+		recent:           map[int]repository.RecentSends{},
+		deferred:         map[int]time.Time{},
+	}
+}
+
+func (m *MockCapRepo) GetByID(id int) (*model.Campaign, error) {
+	c, _ := m.MockDispatchRepo.GetByID(id)
+	c.FrequencyCapPolicy = m.policy
+	return c, nil
+}
+
+func (m *MockCapRepo) RecentSends(customerIDs []int, channel string, since time.Time, excludeCampaignID int, includePending bool) (map[int]repository.RecentSends, error) {
+	return m.recent, nil
+}
+
+func (m *MockCapRepo) HoldOutboundMessage(id int, status string, notBefore time.Time, reason string) error {
+	m.statuses[id] = status
+	m.deferred[id] = notBefore
+	return nil
+}
+
+func (m *MockCapRepo) ReleaseDueMessages(now time.Time) ([]int, error) {
+	return m.due, nil
+}
+
+func TestParseFrequencyCap(t *testing.T) {
+	if f, err := service.ParseFrequencyCap("", ""); f != nil || err != nil {
+		t.Errorf("expected no cap when unset, got %+v (%v)", f, err)
+	}
+	f, err := service.ParseFrequencyCap("2", "")
+	if err != nil || f.Max != 2 || f.Window != 24*time.Hour {
+		t.Errorf("expected 2 per 24h, got %+v (%v)", f, err)
+	}
+	for _, c := range [][2]string{{"0", ""}, {"two", ""}, {"2", "1 day"}, {"2", "-1h"}} {
+		if _, err := service.ParseFrequencyCap(c[0], c[1]); err == nil {
+			t.Errorf("%v: expected an error", c)
+		}
+	}
+}
+
+func TestSendCampaignFrequencyCap(t *testing.T) {
+	oldest := time.Now().Add(-3 * time.Hour)
+
+	for _, policy := range []string{"defer", "skip"} {
+		repo := newMockCapRepo(policy)
+		repo.recent[2] = repository.RecentSends{Count: 2, Oldest: oldest}
+		repo.recent[1] = repository.RecentSends{Count: 1, Oldest: oldest}
+
+		q := &MockQueue{}
+		svc := &service.CampaignService{
+			CampaignRepo: repo,
+			CustomerRepo: &MockCustomerRepo{},
+			FrequencyCap: &service.FrequencyCap{Max: 2, Window: 24 * time.Hour},
+			Queue:        q,
+		}
+
+		result, err := svc.SendCampaign(1, []int{1, 2})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", policy, err)
+		}
+		if result.MessagesQueued != 1 || result.Capped != 1 || len(q.published) != 1 {
+			t.Errorf("%s: expected 1 queued and 1 capped, got %d queued, %d capped", policy, result.MessagesQueued, result.Capped)
+		}
+		if want := map[string]string{"defer": "deferred", "skip": "skipped"}[policy]; repo.statuses[2] != want {
+			t.Errorf("%s: expected message 2 to be %s, got %q", policy, want, repo.statuses[2])
+		}
+		if policy == "defer" && !repo.deferred[2].Equal(oldest.Add(24*time.Hour)) {
+			t.Errorf("expected deferral until the oldest message leaves the window, got %v", repo.deferred[2])
+		}
+	}
+}
+
+func TestDeliverFrequencyCapAndRelease(t *testing.T) {
+	repo := newMockCapRepo("defer")
+	repo.recent[2] = repository.RecentSends{Count: 2, Oldest: time.Now().Add(-time.Hour)}
+
+	q := &MockQueue{}
+	sent := []int{}
+	d := &service.DispatchService{
+		CampaignRepo: repo,
+		FrequencyCap: &service.FrequencyCap{Max: 2, Window: 24 * time.Hour},
+		Send: func(msg *model.OutboundMessage) error {
+			sent = append(sent, msg.ID)
+			return nil
+		},
+		Queue: q,
+	}
+
+	for _, id := range []int{1, 2} {
+		if err := d.Deliver(id); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(sent) != 1 || repo.statuses[2] != "deferred" {
+		t.Errorf("expected message 2 to be deferred, sent %v, statuses %v", sent, repo.statuses)
+	}
+
+	repo.due = []int{2}
+	n, err := d.ReleaseDue()
+	if err != nil || n != 1 || len(q.published) != 1 || q.published[0] != 2 {
+		t.Errorf("expected message 2 to be requeued, got %d (%v), published %v", n, err, q.published)
+	}
+}
+
+func TestFrequencyCapPolicyValidation(t *testing.T) {
+	svc := &service.CampaignService{CampaignRepo: &MockCampaignPaginationRepo{}}
+
+	c, err := svc.CreateCampaign(service.CampaignInput{Name: "Sale", Channel: "sms", BaseTemplate: "Hi {first_name}"})
+	if err != nil || c.FrequencyCapPolicy != "defer" {
+		t.Errorf("expected defer by default, got %+v (%v)", c, err)
+	}
+
+	_, err = svc.CreateCampaign(service.CampaignInput{Name: "Sale", Channel: "sms", BaseTemplate: "Hi", FrequencyCapPolicy: "drop"})
+	var validation *appErrors.ErrValidation
+	if !errors.As(err, &validation) || validation.Field != "frequency_cap_policy" {
+		t.Errorf("expected invalid policy to be rejected, got %v", err)
+	}
+}
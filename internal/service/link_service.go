@@ -0,0 +1,81 @@
+// internal/service/link_service.go
+package service
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/repository"
+	"github.com/unclebandit/smsleopard-backend/internal/template"
+)
+
+// ShortCodeLength is the number of characters in a short link code
+const ShortCodeLength = 7
+
+const shortCodeAlphabet = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// LinkService resolves tracked short links
+type LinkService struct {
+	Repo repository.ShortLinkRepositoryInterface
+}
+
+// Follow records a click on the link with the given code and returns its target URL
+func (s *LinkService) Follow(code, userAgent, ipAddress string) (string, error) {
+	link, err := s.Repo.GetByCode(code)
+	if err != nil {
+		return "", err
+	}
+	if err := s.Repo.RecordClick(link.ID, userAgent, ipAddress); err != nil {
+		return "", err
+	}
+	return link.TargetURL, nil
+}
+
+// newShortCode returns a random code without look-alike characters such as 0/O and 1/l
+func newShortCode() (string, error) {
+	max := big.NewInt(int64(len(shortCodeAlphabet)))
+	code := make([]byte, ShortCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = shortCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// shortURL is the public URL of a short link code
+func (s *CampaignService) shortURL(code string) string {
+	return strings.TrimRight(s.LinkBaseURL, "/") + "/l/" + code
+}
+
+// linkerFor returns the template.Linker used when rendering a message. Each target gets
+// a short link tracked against the message; *errp receives the first failure. Previews
+// (messageID 0) get a placeholder URL of the same length so segment counts stay accurate.
+// Without a link repository targets are left unchanged.
+func (s *CampaignService) linkerFor(messageID int, errp *error) template.Linker {
+	if messageID == 0 {
+		return func(string) string {
+			return s.shortURL(strings.Repeat("x", ShortCodeLength))
+		}
+	}
+	if s.LinkRepo == nil {
+		return nil
+	}
+	return func(target string) string {
+		code, err := newShortCode()
+		if err == nil {
+			var link *model.ShortLink
+			if link, err = s.LinkRepo.GetOrCreate(messageID, target, code); err == nil {
+				return s.shortURL(link.Code)
+			}
+		}
+		if *errp == nil {
+			*errp = err
+		}
+		return target
+	}
+}
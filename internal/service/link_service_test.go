@@ -0,0 +1,107 @@
+package service_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+// MockShortLinkRepo keeps links in memory, keyed by code
+type MockShortLinkRepo struct {
+	links  map[string]*model.ShortLink
+	clicks map[int]int
+}
+
+func NewMockShortLinkRepo() *MockShortLinkRepo {
+	return &MockShortLinkRepo{links: map[string]*model.ShortLink{}, clicks: map[int]int{}}
+}
+
+func (m *MockShortLinkRepo) GetOrCreate(messageID int, target, code string) (*model.ShortLink, error) {
+	for _, l := range m.links {
+		if l.OutboundMessageID == messageID && l.TargetURL == target {
+			return l, nil
+		}
+	}
+	l := &model.ShortLink{ID: len(m.links) + 1, Code: code, OutboundMessageID: messageID, TargetURL: target}
+	m.links[code] = l
+	return l, nil
+}
+
+func (m *MockShortLinkRepo) GetByCode(code string) (*model.ShortLink, error) {
+	if l, ok := m.links[code]; ok {
+		return l, nil
+	}
+	return nil, appErrors.NewShortLinkNotFound(code)
+}
+
+func (m *MockShortLinkRepo) RecordClick(linkID int, userAgent, ipAddress string) error {
+	m.clicks[linkID]++
+	return nil
+}
+
+func TestRenderOutboundCreatesTrackedLinks(t *testing.T) {
+	links := NewMockShortLinkRepo()
+	svc := &service.CampaignService{LinkRepo: links, LinkBaseURL: "https://go.example/"}
+	campaign := &model.Campaign{Channel: "sms", BaseTemplate: "Hi {first_name}: {link:https://shop.example/sale}"}
+	customer := &model.Customer{ID: 1, FirstName: "Alice"}
+
+	msg := &model.OutboundMessage{ID: 10}
+	if err := svc.RenderOutbound(campaign, customer, msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(links.links) != 1 {
+		t.Fatalf("expected one short link, got %d", len(links.links))
+	}
+
+	var link *model.ShortLink
+	for _, l := range links.links {
+		link = l
+	}
+	if want := "Hi Alice: https://go.example/l/" + link.Code; msg.RenderedContent != want {
+		t.Errorf("expected %q, got %q", want, msg.RenderedContent)
+	}
+	if len(link.Code) != service.ShortCodeLength || link.OutboundMessageID != 10 {
+		t.Errorf("unexpected link %+v", link)
+	}
+
+	// re-rendering the same message reuses its link
+	again := &model.OutboundMessage{ID: 10}
+	if err := svc.RenderOutbound(campaign, customer, again); err != nil || again.RenderedContent != msg.RenderedContent {
+		t.Errorf("expected re-render to reuse %q, got %q (%v)", msg.RenderedContent, again.RenderedContent, err)
+	}
+
+	// previews are not tracked but keep the short URL length
+	preview, err := svc.RenderMessage(campaign, customer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(preview) != len(msg.RenderedContent) || !strings.HasPrefix(preview, "Hi Alice: https://go.example/l/") {
+		t.Errorf("unexpected preview %q", preview)
+	}
+	if len(links.links) != 1 {
+		t.Errorf("expected previews not to create links, got %d", len(links.links))
+	}
+}
+
+func TestFollowRecordsClick(t *testing.T) {
+	links := NewMockShortLinkRepo()
+	link, _ := links.GetOrCreate(10, "https://shop.example/sale", "abc1234")
+	svc := &service.LinkService{Repo: links}
+
+	target, err := svc.Follow("abc1234", "test-agent", "127.0.0.1")
+	if err != nil || target != "https://shop.example/sale" {
+		t.Fatalf("expected redirect target, got %q (%v)", target, err)
+	}
+	if links.clicks[link.ID] != 1 {
+		t.Errorf("expected one click recorded, got %d", links.clicks[link.ID])
+	}
+
+	var notFound *appErrors.ErrShortLinkNotFound
+	if _, err := svc.Follow("missing", "", ""); !errors.As(err, &notFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+// internal/service/phone_backfill.go
+package service
+
+import (
+	"sort"
+
+	"github.com/unclebandit/smsleopard-backend/internal/phone"
+)
+
+// PhoneIssue is a stored phone the backfill could not normalize
+type PhoneIssue struct {
+	CustomerID int    `json:"customer_id"`
+	Phone      string `json:"phone"`
+	Reason     string `json:"reason"`
+}
+
+// PhoneDuplicate is a set of customers whose phones are the same number once normalized
+type PhoneDuplicate struct {
+	Phone       string `json:"phone"`
+	CustomerIDs []int  `json:"customer_ids"`
+}
+
+// PhoneBackfillReport summarises a NormalizePhones run
+type PhoneBackfillReport struct {
+	Checked    int              `json:"checked"`
+	Updated    int              `json:"updated"`
+	Invalid    []PhoneIssue     `json:"invalid"`
+	Duplicates []PhoneDuplicate `json:"duplicates"`
+}
+
+// NormalizePhones converts the phones of existing customers to E.164. Customers whose
+// phones collide once normalized are reported and left unchanged so they can be merged
+// by hand, as are phones that cannot be normalized. With dryRun nothing is written.
+func (s *CustomerService) NormalizePhones(dryRun bool) (*PhoneBackfillReport, error) {
+	phones := s.Phones
+	if phones == nil {
+		phones = phone.Default
+	}
+
+	customers, err := s.Repo.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(customers, func(i, j int) bool { return customers[i].ID < customers[j].ID })
+
+	report := &PhoneBackfillReport{Invalid: []PhoneIssue{}, Duplicates: []PhoneDuplicate{}}
+	normalized := map[int]string{}
+	owners := map[string][]int{}
+	for _, c := range customers {
+		if c.ErasedAt != nil {
+			continue // phone already replaced by a placeholder
+		}
+		report.Checked++
+		e164, err := phones.Normalize(c.Phone)
+		if err != nil {
+			report.Invalid = append(report.Invalid, PhoneIssue{CustomerID: c.ID, Phone: c.Phone, Reason: err.Error()})
+			continue
+		}
+		normalized[c.ID] = e164
+		owners[e164] = append(owners[e164], c.ID)
+	}
+
+	for _, c := range customers {
+		e164, ok := normalized[c.ID]
+		if !ok || len(owners[e164]) > 1 || e164 == c.Phone {
+			continue
+		}
+		if !dryRun {
+			c.Phone = e164
+			if err := s.Repo.Update(&c); err != nil {
+				return report, err
+			}
+		}
+		report.Updated++
+	}
+
+	for e164, ids := range owners {
+		if len(ids) > 1 {
+			report.Duplicates = append(report.Duplicates, PhoneDuplicate{Phone: e164, CustomerIDs: ids})
+		}
+	}
+	sort.Slice(report.Duplicates, func(i, j int) bool { return report.Duplicates[i].Phone < report.Duplicates[j].Phone })
+	return report, nil
+}
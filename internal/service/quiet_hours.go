@@ -0,0 +1,108 @@
+// internal/service/quiet_hours.go
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // customer timezones must resolve on hosts without a zoneinfo database
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+)
+
+// QuietHoursPolicy decides when messages may be delivered
+type QuietHoursPolicy struct {
+	Default  *model.QuietHours // applies to campaigns without their own; nil means none
+	Location *time.Location    // timezone of customers who have none set
+}
+
+// ParseQuietHours reads the global policy from configuration values such as "21:00-08:00"
+// and "Africa/Nairobi". An empty window means no default quiet hours; the timezone defaults to UTC.
+func ParseQuietHours(window, timezone string) (*QuietHoursPolicy, error) {
+	p := &QuietHoursPolicy{Location: time.UTC}
+	if timezone != "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("unknown default timezone %q", timezone)
+		}
+		p.Location = loc
+	}
+	if window != "" {
+		start, end, ok := strings.Cut(window, "-")
+		q := &model.QuietHours{Start: strings.TrimSpace(start), End: strings.TrimSpace(end)}
+		if !ok || validateQuietHours(q) != nil {
+			return nil, fmt.Errorf("quiet hours must look like 21:00-08:00, got %q", window)
+		}
+		p.Default = q
+	}
+	return p, nil
+}
+
+// Until reports whether now falls inside the quiet hours that apply to campaign for a customer
+// in timezone, and if so when they end. An unknown or empty timezone uses the policy's default.
+func (p *QuietHoursPolicy) Until(campaign *model.Campaign, timezone string, now time.Time) (time.Time, bool) {
+	if p == nil {
+		return time.Time{}, false
+	}
+	hours := p.Default
+	if campaign.QuietHours != nil {
+		hours = campaign.QuietHours
+	}
+	if hours == nil {
+		return time.Time{}, false
+	}
+	start, err1 := parseClock(hours.Start)
+	end, err2 := parseClock(hours.End)
+	if err1 != nil || err2 != nil || start == end {
+		return time.Time{}, false
+	}
+
+	loc := p.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	if timezone != "" {
+		if l, err := time.LoadLocation(timezone); err == nil {
+			loc = l
+		}
+	}
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+
+	var quiet bool
+	if start < end {
+		quiet = minute >= start && minute < end
+	} else {
+		quiet = minute >= start || minute < end // crosses midnight
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	opens := time.Date(local.Year(), local.Month(), local.Day(), end/60, end%60, 0, 0, loc)
+	if !opens.After(local) {
+		opens = opens.AddDate(0, 0, 1)
+	}
+	return opens, true
+}
+
+// parseClock converts "HH:MM" into minutes past midnight
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// validateQuietHours checks a campaign's quiet hours override
+func validateQuietHours(q *model.QuietHours) error {
+	if _, err := parseClock(q.Start); err != nil {
+		return appErrors.NewValidation("quiet_hours", "start must be a time like 21:00")
+	}
+	if _, err := parseClock(q.End); err != nil {
+		return appErrors.NewValidation("quiet_hours", "end must be a time like 08:00")
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+func TestParseQuietHours(t *testing.T) {
+	p, err := service.ParseQuietHours("21:00-08:00", "Africa/Nairobi")
+	if err != nil || p.Default.Start != "21:00" || p.Default.End != "08:00" || p.Location.String() != "Africa/Nairobi" {
+		t.Errorf("unexpected policy %+v (%v)", p, err)
+	}
+	if p, err := service.ParseQuietHours("", ""); err != nil || p.Default != nil {
+		t.Errorf("expected no default quiet hours, got %+v (%v)", p, err)
+	}
+	for _, c := range [][2]string{{"21:00", ""}, {"9pm-8am", ""}, {"21:00-08:00", "Mars/Olympus"}} {
+		if _, err := service.ParseQuietHours(c[0], c[1]); err == nil {
+			t.Errorf("%v: expected an error", c)
+		}
+	}
+}
+
+func TestQuietHoursUntil(t *testing.T) {
+	p, _ := service.ParseQuietHours("21:00-08:00", "Africa/Nairobi") // UTC+3
+	campaign := &model.Campaign{}
+	day := func(h, m int) time.Time { return time.Date(2026, 3, 10, h, m, 0, 0, time.UTC) }
+
+	cases := []struct {
+		name     string
+		campaign *model.Campaign
+		timezone string
+		now      time.Time
+		quiet    bool
+		until    time.Time
+	}{
+		{"late evening", campaign, "", day(19, 30), true, day(5, 0).AddDate(0, 0, 1)},
+		{"early morning", campaign, "", day(2, 0), true, day(5, 0)},
+		{"window open", campaign, "", day(12, 0), false, time.Time{}},
+		{"opens at end", campaign, "", day(5, 0), false, time.Time{}},
+		{"customer timezone", campaign, "Europe/London", day(19, 30), false, time.Time{}},
+		{"unknown timezone uses default", campaign, "Nowhere/Else", day(19, 30), true, day(5, 0).AddDate(0, 0, 1)},
+		{"daytime override", &model.Campaign{QuietHours: &model.QuietHours{Start: "12:00", End: "14:00"}}, "", day(9, 30), true, day(11, 0)},
+		{"override turned off", &model.Campaign{QuietHours: &model.QuietHours{Start: "00:00", End: "00:00"}}, "", day(2, 0), false, time.Time{}},
+	}
+	for _, c := range cases {
+		until, quiet := p.Until(c.campaign, c.timezone, c.now)
+		if quiet != c.quiet || !until.Equal(c.until) {
+			t.Errorf("%s: got %v until %v, want %v until %v", c.name, quiet, until, c.quiet, c.until)
+		}
+	}
+
+	var none *service.QuietHoursPolicy
+	if _, quiet := none.Until(campaign, "", day(2, 0)); quiet {
+		t.Error("expected no quiet hours without a policy")
+	}
+}
+
+func TestDeliverHoldsDuringQuietHours(t *testing.T) {
+	repo := newMockCapRepo("defer")
+	sent := 0
+	d := &service.DispatchService{
+		CampaignRepo: repo,
+		CustomerRepo: &MockCustomerRepo{},
+		// quiet all day except the minute before midnight, so the test hour does not matter
+		QuietHours: &service.QuietHoursPolicy{Default: &model.QuietHours{Start: "00:00", End: "23:59"}, Location: time.UTC},
+		Send: func(msg *model.OutboundMessage) error {
+			sent++
+			return nil
+		},
+	}
+	if time.Now().UTC().Format("15:04") == "23:59" {
+		t.Skip("inside the open minute")
+	}
+
+	if err := d.Deliver(1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sent != 0 || repo.statuses[1] != "held" || repo.deferred[1].IsZero() {
+		t.Errorf("expected message to be held, sent %d, statuses %v", sent, repo.statuses)
+	}
+}
+
+func TestCampaignQuietHoursValidation(t *testing.T) {
+	svc := &service.CampaignService{CampaignRepo: &MockCampaignPaginationRepo{}}
+
+	_, err := svc.CreateCampaign(service.CampaignInput{
+		Name: "Sale", Channel: "sms", BaseTemplate: "Hi",
+		QuietHours: &model.QuietHours{Start: "25:00", End: "08:00"},
+	})
+	var validation *appErrors.ErrValidation
+	if !errors.As(err, &validation) || validation.Field != "quiet_hours" {
+		t.Errorf("expected invalid quiet hours to be rejected, got %v", err)
+	}
+}
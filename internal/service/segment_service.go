@@ -0,0 +1,125 @@
+// internal/service/segment_service.go
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/repository"
+	"github.com/unclebandit/smsleopard-backend/internal/template"
+)
+
+// SegmentService manages saved audience segments
+type SegmentService struct {
+	Repo repository.SegmentRepositoryInterface
+}
+
+// SegmentDetails is a segment plus the number of customers it currently matches
+type SegmentDetails struct {
+	*model.Segment
+	CustomerCount int `json:"customer_count"`
+}
+
+// Create validates and stores a segment
+func (s *SegmentService) Create(seg *model.Segment) (*SegmentDetails, error) {
+	seg.Name = strings.TrimSpace(seg.Name)
+	if seg.Name == "" {
+		return nil, appErrors.NewValidation("name", "is required")
+	}
+	if err := validateSegmentRules(seg.Rules); err != nil {
+		return nil, err
+	}
+	if seg.Rules == nil {
+		seg.Rules = []model.SegmentRule{}
+	}
+	if err := s.Repo.Create(seg); err != nil {
+		return nil, err
+	}
+	return s.details(seg)
+}
+
+// Get fetches a segment with its current size
+func (s *SegmentService) Get(id int) (*SegmentDetails, error) {
+	seg, err := s.Repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.details(seg)
+}
+
+// List returns all saved segments
+func (s *SegmentService) List() ([]model.Segment, error) {
+	return s.Repo.List()
+}
+
+// Preview counts the customers that unsaved rules would match
+func (s *SegmentService) Preview(rules []model.SegmentRule) (int, error) {
+	if err := validateSegmentRules(rules); err != nil {
+		return 0, err
+	}
+	return s.Repo.CountCustomers(rules)
+}
+
+func (s *SegmentService) details(seg *model.Segment) (*SegmentDetails, error) {
+	count, err := s.Repo.CountCustomers(seg.Rules)
+	if err != nil {
+		return nil, err
+	}
+	return &SegmentDetails{Segment: seg, CustomerCount: count}, nil
+}
+
+// validateSegmentRules checks fields, operators and values. An empty rule list matches
+// every customer.
+func validateSegmentRules(rules []model.SegmentRule) error {
+	for i, rule := range rules {
+		field := fmt.Sprintf("rules[%d]", i)
+
+		key, isAttribute := strings.CutPrefix(rule.Field, template.AttributePrefix)
+		if isAttribute && (key == "" || strings.Contains(key, ".")) {
+			return appErrors.NewValidation(field, fmt.Sprintf("invalid attribute field %q", rule.Field))
+		}
+		if _, ok := repository.SegmentFields[rule.Field]; !isAttribute && !ok {
+			return appErrors.NewValidation(field, fmt.Sprintf("unknown field %q", rule.Field))
+		}
+
+		switch rule.Op {
+		case "eq", "neq":
+			if len(rule.Values) > 0 {
+				return appErrors.NewValidation(field, fmt.Sprintf("%s takes a single value", rule.Op))
+			}
+		case "in", "not_in":
+			if len(rule.Values) == 0 {
+				return appErrors.NewValidation(field, fmt.Sprintf("%s requires a non-empty values list", rule.Op))
+			}
+		case "gt", "gte", "lt", "lte":
+			if !isAttribute {
+				return appErrors.NewValidation(field, fmt.Sprintf("%s can only compare attr.<key> fields", rule.Op))
+			}
+			if _, err := strconv.ParseFloat(rule.Value, 64); err != nil {
+				return appErrors.NewValidation(field, fmt.Sprintf("%s requires a numeric value", rule.Op))
+			}
+		default:
+			return appErrors.NewValidation(field, fmt.Sprintf("unknown operator %q", rule.Op))
+		}
+	}
+	return nil
+}
+
+// SendCampaignToSegment sends the campaign to every customer currently in the segment
+func (s *CampaignService) SendCampaignToSegment(campaignID, segmentID int) (*SendCampaignResult, error) {
+	if s.SegmentRepo == nil {
+		return nil, fmt.Errorf("segments are not configured")
+	}
+	seg, err := s.SegmentRepo.GetByID(segmentID)
+	if err != nil {
+		return nil, err
+	}
+	customerIDs, err := s.SegmentRepo.CustomerIDs(seg.Rules)
+	if err != nil {
+		return nil, err
+	}
+	return s.SendCampaign(campaignID, customerIDs)
+}
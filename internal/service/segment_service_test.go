@@ -0,0 +1,174 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+// MockSegmentRepo matches customers against eq/in rules in memory
+type MockSegmentRepo struct {
+	segments  map[int]*model.Segment
+	customers []model.Customer
+}
+
+func (m *MockSegmentRepo) Create(s *model.Segment) error {
+	s.ID = len(m.segments) + 1
+	m.segments[s.ID] = s
+	return nil
+}
+
+func (m *MockSegmentRepo) GetByID(id int) (*model.Segment, error) {
+	if s, ok := m.segments[id]; ok {
+		return s, nil
+	}
+	return nil, appErrors.NewSegmentNotFound(id)
+}
+
+func (m *MockSegmentRepo) List() ([]model.Segment, error) {
+	segments := []model.Segment{}
+	for _, s := range m.segments {
+		segments = append(segments, *s)
+	}
+	return segments, nil
+}
+
+func (m *MockSegmentRepo) CountCustomers(rules []model.SegmentRule) (int, error) {
+	ids, err := m.CustomerIDs(rules)
+	return len(ids), err
+}
+
+func (m *MockSegmentRepo) CustomerIDs(rules []model.SegmentRule) ([]int, error) {
+	ids := []int{}
+	for _, c := range m.customers {
+		values := map[string]string{"location": c.Location, "preferred_product": c.PreferredProduct}
+		matches := true
+		for _, r := range rules {
+			switch r.Op {
+			case "eq":
+				matches = matches && values[r.Field] == r.Value
+			case "in":
+				found := false
+				for _, v := range r.Values {
+					found = found || values[r.Field] == v
+				}
+				matches = matches && found
+			}
+		}
+		if matches {
+			ids = append(ids, c.ID)
+		}
+	}
+	return ids, nil
+}
+
+// MockSendRepo returns draft campaigns so SendCampaign can run against it
+type MockSendRepo struct {
+	MockCampaignPaginationRepo
+	nextID int
+}
+
+func (m *MockSendRepo) GetByID(id int) (*model.Campaign, error) {
+	return &model.Campaign{ID: id, Channel: "sms", Status: "draft", BaseTemplate: "Hi {first_name}"}, nil
+}
+
+func (m *MockSendRepo) CreateOutboundMessage(campaignID, customerID int) (*model.OutboundMessage, error) {
+	m.nextID++
+	return &model.OutboundMessage{ID: m.nextID, CampaignID: campaignID, CustomerID: customerID, Status: "pending"}, nil
+}
+
+// MockQueue records published payloads
+type MockQueue struct {
+	published []interface{}
+}
+
+func (q *MockQueue) Publish(topic string, payload any) error {
+	q.published = append(q.published, payload)
+	return nil
+}
+
+func (q *MockQueue) Subscribe(topic string, handler func(payload any) error) error { return nil }
+
+func newMockSegmentRepo() *MockSegmentRepo {
+	return &MockSegmentRepo{
+		segments: map[int]*model.Segment{},
+		customers: []model.Customer{
+			{ID: 1, Location: "Nairobi", PreferredProduct: "Shoes"},
+			{ID: 2, Location: "Mombasa", PreferredProduct: "Shoes"},
+			{ID: 3, Location: "Kisumu", PreferredProduct: "Hat"},
+		},
+	}
+}
+
+func TestSegmentCreateAndPreview(t *testing.T) {
+	svc := &service.SegmentService{Repo: newMockSegmentRepo()}
+
+	seg, err := svc.Create(&model.Segment{Name: "Coastal shoe buyers", Rules: []model.SegmentRule{
+		{Field: "location", Op: "in", Values: []string{"Nairobi", "Mombasa"}},
+		{Field: "preferred_product", Op: "eq", Value: "Shoes"},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if seg.CustomerCount != 2 {
+		t.Errorf("expected 2 customers, got %d", seg.CustomerCount)
+	}
+
+	count, err := svc.Preview([]model.SegmentRule{{Field: "preferred_product", Op: "eq", Value: "Hat"}})
+	if err != nil || count != 1 {
+		t.Errorf("expected preview of 1 customer, got %d (%v)", count, err)
+	}
+}
+
+func TestSegmentRuleValidation(t *testing.T) {
+	svc := &service.SegmentService{Repo: newMockSegmentRepo()}
+
+	cases := []model.SegmentRule{
+		{Field: "phone", Op: "eq", Value: "1"},
+		{Field: "location", Op: "like", Value: "Nai%"},
+		{Field: "location", Op: "in"},
+		{Field: "location", Op: "gt", Value: "1"},
+		{Field: "attr.points", Op: "gte", Value: "lots"},
+		{Field: "attr.", Op: "eq", Value: "x"},
+	}
+	for _, rule := range cases {
+		_, err := svc.Preview([]model.SegmentRule{rule})
+		var validation *appErrors.ErrValidation
+		if !errors.As(err, &validation) || validation.Field != "rules[0]" {
+			t.Errorf("%+v: expected validation error, got %v", rule, err)
+		}
+	}
+
+	if _, err := svc.Preview([]model.SegmentRule{{Field: "attr.points", Op: "gte", Value: "100"}}); err != nil {
+		t.Errorf("expected attribute comparison to be accepted, got %v", err)
+	}
+}
+
+func TestSendCampaignToSegment(t *testing.T) {
+	segments := newMockSegmentRepo()
+	segments.Create(&model.Segment{Name: "Shoes", Rules: []model.SegmentRule{{Field: "preferred_product", Op: "eq", Value: "Shoes"}}})
+
+	q := &MockQueue{}
+	svc := &service.CampaignService{
+		CampaignRepo: &MockSendRepo{},
+		CustomerRepo: &MockCustomerRepo{},
+		SegmentRepo:  segments,
+		Queue:        q,
+	}
+
+	result, err := svc.SendCampaignToSegment(1, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.MessagesQueued != 2 || len(q.published) != 2 {
+		t.Errorf("expected 2 messages queued, got %d (published %d)", result.MessagesQueued, len(q.published))
+	}
+
+	var notFound *appErrors.ErrSegmentNotFound
+	if _, err := svc.SendCampaignToSegment(1, 42); !errors.As(err, &notFound) {
+		t.Errorf("expected segment not found, got %v", err)
+	}
+}
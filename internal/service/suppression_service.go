@@ -0,0 +1,62 @@
+// internal/service/suppression_service.go
+package service
+
+import (
+	"strings"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/repository"
+)
+
+// suppressionChannels are the channels a customer can opt out of; "all" covers every channel
+var suppressionChannels = map[string]bool{"sms": true, "whatsapp": true, "all": true}
+
+// SuppressionService manages the opt-out list sends are checked against
+type SuppressionService struct {
+	Repo repository.SuppressionRepositoryInterface
+}
+
+// Add puts a customer on the suppression list for a channel
+func (s *SuppressionService) Add(sup *model.Suppression) error {
+	sup.Channel = strings.ToLower(strings.TrimSpace(sup.Channel))
+	if sup.CustomerID <= 0 {
+		return appErrors.NewValidation("customer_id", "is required")
+	}
+	if !suppressionChannels[sup.Channel] {
+		return appErrors.NewValidation("channel", "must be sms, whatsapp or all")
+	}
+	sup.Reason = strings.TrimSpace(sup.Reason)
+	return s.Repo.Create(sup)
+}
+
+// Remove deletes an entry, returning ErrSuppressionNotFound when it does not exist
+func (s *SuppressionService) Remove(id int) error {
+	return s.Repo.Delete(id)
+}
+
+// List returns a page of entries, optionally for one customer and channel
+func (s *SuppressionService) List(page, pageSize, customerID int, channel string) ([]model.Suppression, map[string]int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	suppressions, total, err := s.Repo.List(customerID, channel, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := map[string]int{
+		"page":        page,
+		"page_size":   pageSize,
+		"total_count": total,
+		"total_pages": (total + pageSize - 1) / pageSize,
+	}
+	return suppressions, pagination, nil
+}
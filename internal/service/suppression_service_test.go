@@ -0,0 +1,166 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+// MockSuppressionRepo keeps the suppression list in memory
+type MockSuppressionRepo struct {
+	entries []model.Suppression
+}
+
+func (m *MockSuppressionRepo) Create(s *model.Suppression) error {
+	for i, e := range m.entries {
+		if e.CustomerID == s.CustomerID && e.Channel == s.Channel {
+			m.entries[i].Reason = s.Reason
+			s.ID = e.ID
+			return nil
+		}
+	}
+	s.ID = len(m.entries) + 1
+	m.entries = append(m.entries, *s)
+	return nil
+}
+
+func (m *MockSuppressionRepo) Delete(id int) error {
+	for i, e := range m.entries {
+		if e.ID == id {
+			m.entries = append(m.entries[:i], m.entries[i+1:]...)
+			return nil
+		}
+	}
+	return appErrors.NewSuppressionNotFound(id)
+}
+
+func (m *MockSuppressionRepo) List(customerID int, channel string, offset, limit int) ([]model.Suppression, int, error) {
+	return m.entries, len(m.entries), nil
+}
+
+func (m *MockSuppressionRepo) Suppressed(customerIDs []int, channel string) (map[int]string, error) {
+	suppressed := map[int]string{}
+	for _, id := range customerIDs {
+		for _, e := range m.entries {
+			if e.CustomerID == id && (e.Channel == channel || e.Channel == "all") {
+				suppressed[id] = e.Reason
+			}
+		}
+	}
+	return suppressed, nil
+}
+
+// MockDispatchRepo records the status each outbound message ends up in
+type MockDispatchRepo struct {
+	MockSendRepo
+	statuses map[int]string
+}
+
+func (m *MockDispatchRepo) GetOutboundMessageByID(id int) (*model.OutboundMessage, error) {
+	status := m.statuses[id]
+	if status == "" {
+		status = "pending"
+	}
+	return &model.OutboundMessage{ID: id, CampaignID: 1, CustomerID: id, Status: status, RenderedContent: "Hi"}, nil
+}
+
+// ClaimOutboundMessage succeeds once for a pending or retrying message, like the SQL update
+func (m *MockDispatchRepo) ClaimOutboundMessage(id int) (bool, error) {
+	if status := m.statuses[id]; status != "" && status != "pending" && status != "retrying" {
+		return false, nil
+	}
+	m.statuses[id] = "sending"
+	return true, nil
+}
+
+func (m *MockDispatchRepo) UpdateOutboundMessageStatus(id int, status, lastError string) error {
+	m.statuses[id] = status
+	return nil
+}
+
+func TestSuppressionValidation(t *testing.T) {
+	svc := &service.SuppressionService{Repo: &MockSuppressionRepo{}}
+
+	cases := map[string]model.Suppression{
+		"customer_id": {Channel: "sms"},
+		"channel":     {CustomerID: 1, Channel: "email"},
+	}
+	for field, s := range cases {
+		var validation *appErrors.ErrValidation
+		if err := svc.Add(&s); !errors.As(err, &validation) || validation.Field != field {
+			t.Errorf("expected validation error on %s, got %v", field, err)
+		}
+	}
+
+	s := &model.Suppression{CustomerID: 1, Channel: " SMS ", Reason: "replied STOP"}
+	if err := svc.Add(s); err != nil || s.Channel != "sms" {
+		t.Errorf("expected normalised channel, got %q (%v)", s.Channel, err)
+	}
+
+	var notFound *appErrors.ErrSuppressionNotFound
+	if err := svc.Remove(42); !errors.As(err, &notFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+func TestSendCampaignSkipsSuppressed(t *testing.T) {
+	suppressions := &MockSuppressionRepo{}
+	suppressions.Create(&model.Suppression{CustomerID: 2, Channel: "sms", Reason: "replied STOP"})
+	suppressions.Create(&model.Suppression{CustomerID: 1, Channel: "whatsapp"})
+
+	repo := &MockDispatchRepo{statuses: map[int]string{}}
+	q := &MockQueue{}
+	svc := &service.CampaignService{
+		CampaignRepo:    repo,
+		CustomerRepo:    &MockCustomerRepo{},
+		SuppressionRepo: suppressions,
+		Queue:           q,
+	}
+
+	result, err := svc.SendCampaign(1, []int{1, 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.MessagesQueued != 1 || result.Suppressed != 1 || len(q.published) != 1 {
+		t.Errorf("expected 1 queued and 1 suppressed, got %d queued, %d suppressed", result.MessagesQueued, result.Suppressed)
+	}
+	if repo.statuses[2] != "suppressed" {
+		t.Errorf("expected message 2 to be suppressed, got %q", repo.statuses[2])
+	}
+}
+
+func TestDeliverSkipsSuppressed(t *testing.T) {
+	suppressions := &MockSuppressionRepo{}
+	suppressions.Create(&model.Suppression{CustomerID: 2, Channel: "all"})
+
+	repo := &MockDispatchRepo{statuses: map[int]string{}}
+	sent := []int{}
+	d := &service.DispatchService{
+		CampaignRepo:    repo,
+		SuppressionRepo: suppressions,
+		Send: func(msg *model.OutboundMessage) error {
+			sent = append(sent, msg.ID)
+			return nil
+		},
+	}
+
+	for _, id := range []int{1, 2} {
+		if err := d.Deliver(id); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(sent) != 1 || sent[0] != 1 {
+		t.Errorf("expected only message 1 to be sent, got %v", sent)
+	}
+	if repo.statuses[1] != "sent" || repo.statuses[2] != "suppressed" {
+		t.Errorf("unexpected statuses: %v", repo.statuses)
+	}
+
+	// a redelivered job for a suppressed message is not sent again
+	if err := d.Deliver(2); err != nil || len(sent) != 1 {
+		t.Errorf("expected suppressed message to be skipped, sent %v (%v)", sent, err)
+	}
+}
@@ -0,0 +1,75 @@
+// internal/service/variant.go
+package service
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+)
+
+// AssignVariant deterministically picks the A/B variant a customer receives. The same
+// campaign and customer always hash to the same bucket, so re-sends and previews agree.
+// It returns nil when the campaign has no variants.
+func AssignVariant(campaign *model.Campaign, customerID int) *model.CampaignVariant {
+	if len(campaign.Variants) == 0 {
+		return nil
+	}
+
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%d:%d", campaign.ID, customerID)
+	bucket := int(h.Sum32() % 100)
+
+	cumulative := 0
+	for i := range campaign.Variants {
+		cumulative += campaign.Variants[i].Weight
+		if bucket < cumulative {
+			return &campaign.Variants[i]
+		}
+	}
+	return &campaign.Variants[len(campaign.Variants)-1]
+}
+
+// validateVariants checks labels, percentage splits and every variant template
+func validateVariants(c *model.Campaign) error {
+	if len(c.Variants) == 0 {
+		return nil
+	}
+	if len(c.Variants) < 2 {
+		return appErrors.NewValidation("variants", "at least two variants are required")
+	}
+	if c.WhatsAppTemplateID != nil {
+		return appErrors.NewValidation("variants", "cannot be combined with a whatsapp template")
+	}
+	if len(c.TemplateVariants) > 0 {
+		return appErrors.NewValidation("variants", "cannot be combined with template_variants")
+	}
+
+	total := 0
+	seen := map[string]bool{}
+	for _, v := range c.Variants {
+		label := strings.TrimSpace(v.Label)
+		if label == "" {
+			return appErrors.NewValidation("variants", "every variant needs a label")
+		}
+		if seen[label] {
+			return appErrors.NewValidation("variants", fmt.Sprintf("duplicate label %q", label))
+		}
+		seen[label] = true
+
+		if v.Weight <= 0 {
+			return appErrors.NewValidation("variants", fmt.Sprintf("variant %q must have a positive weight", label))
+		}
+		total += v.Weight
+
+		if err := validateTemplate("variants."+label, v.Template, c.Variables); err != nil {
+			return err
+		}
+	}
+	if total != 100 {
+		return appErrors.NewValidation("variants", fmt.Sprintf("weights must add up to 100, got %d", total))
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+func abCampaign() *model.Campaign {
+	return &model.Campaign{
+		ID:      7,
+		Channel: "sms",
+		Variants: []model.CampaignVariant{
+			{Label: "A", Template: "Hi {first_name}", Weight: 30},
+			{Label: "B", Template: "Hey {first_name}!", Weight: 70},
+		},
+	}
+}
+
+func TestAssignVariantIsDeterministicAndWeighted(t *testing.T) {
+	campaign := abCampaign()
+
+	counts := map[string]int{}
+	for id := 1; id <= 2000; id++ {
+		first := service.AssignVariant(campaign, id)
+		if again := service.AssignVariant(campaign, id); again.Label != first.Label {
+			t.Fatalf("customer %d assigned %s then %s", id, first.Label, again.Label)
+		}
+		counts[first.Label]++
+	}
+
+	// 30/70 split within a few percent
+	if counts["A"] < 500 || counts["A"] > 700 {
+		t.Errorf("expected roughly 600 customers in A, got %d (B=%d)", counts["A"], counts["B"])
+	}
+
+	if v := service.AssignVariant(&model.Campaign{}, 1); v != nil {
+		t.Errorf("expected no variant for a campaign without variants, got %+v", v)
+	}
+}
+
+func TestRenderMessageUsesAssignedVariant(t *testing.T) {
+	svc := &service.CampaignService{}
+	campaign := abCampaign()
+	customer := &model.Customer{ID: 42, FirstName: "Amina"}
+
+	got, err := svc.RenderMessage(campaign, customer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{"A": "Hi Amina", "B": "Hey Amina!"}[service.AssignVariant(campaign, customer.ID).Label]
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestVariantValidation(t *testing.T) {
+	svc := &service.CampaignService{CampaignRepo: &MockCampaignPaginationRepo{}}
+
+	cases := []struct {
+		name     string
+		variants []model.CampaignVariant
+		field    string
+	}{
+		{"single variant", []model.CampaignVariant{{Label: "A", Template: "Hi", Weight: 100}}, "variants"},
+		{"weights not 100", []model.CampaignVariant{{Label: "A", Template: "Hi", Weight: 50}, {Label: "B", Template: "Hey", Weight: 40}}, "variants"},
+		{"duplicate label", []model.CampaignVariant{{Label: "A", Template: "Hi", Weight: 50}, {Label: "A", Template: "Hey", Weight: 50}}, "variants"},
+	}
+	for _, tc := range cases {
+		_, err := svc.CreateCampaign(service.CampaignInput{Name: "AB", Channel: "sms", Variants: tc.variants})
+		var validation *appErrors.ErrValidation
+		if !errors.As(err, &validation) || validation.Field != tc.field {
+			t.Errorf("%s: expected validation error on %q, got %v", tc.name, tc.field, err)
+		}
+	}
+
+	_, err := svc.CreateCampaign(service.CampaignInput{Name: "AB", Channel: "sms", Variants: []model.CampaignVariant{
+		{Label: "A", Template: "Hi {first_name}", Weight: 50},
+		{Label: "B", Template: "Hey {firstname}", Weight: 50},
+	}})
+	var invalid *appErrors.ErrInvalidTemplate
+	if !errors.As(err, &invalid) || invalid.Field != "variants.B" {
+		t.Fatalf("expected invalid template in variant B, got %v", err)
+	}
+
+	if _, err := svc.CreateCampaign(service.CampaignInput{Name: "AB", Channel: "sms", Variants: abCampaign().Variants}); err != nil {
+		t.Fatalf("expected valid variants to be accepted, got %v", err)
+	}
+}
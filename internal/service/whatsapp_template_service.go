@@ -0,0 +1,168 @@
+// internal/service/whatsapp_template_service.go
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/repository"
+	"github.com/unclebandit/smsleopard-backend/internal/sms"
+	"github.com/unclebandit/smsleopard-backend/internal/template"
+)
+
+// whatsappParameter matches the positional {{n}} parameters of an approved template body
+var whatsappParameter = regexp.MustCompile(`\{\{(\d+)\}\}`)
+
+var whatsappMediaTypes = map[string]bool{"image": true, "video": true, "document": true}
+
+// WhatsAppTemplateService manages the registry of approved WhatsApp templates
+type WhatsAppTemplateService struct {
+	Repo repository.WhatsAppTemplateRepositoryInterface
+}
+
+// Register validates and stores an approved template
+func (s *WhatsAppTemplateService) Register(t *model.WhatsAppTemplate) error {
+	t.Name = strings.TrimSpace(t.Name)
+	t.Language = strings.TrimSpace(t.Language)
+
+	if t.Name == "" {
+		return appErrors.NewValidation("name", "is required")
+	}
+	if t.Language == "" {
+		return appErrors.NewValidation("language", "is required")
+	}
+	if t.ParameterCount < 0 {
+		return appErrors.NewValidation("parameter_count", "cannot be negative")
+	}
+	if t.Body != "" {
+		if highest := highestParameter(t.Body); highest != t.ParameterCount {
+			return appErrors.NewValidation("parameter_count", fmt.Sprintf("body uses %d parameter(s) but parameter_count is %d", highest, t.ParameterCount))
+		}
+	}
+	if t.HeaderMediaType != "" {
+		if !whatsappMediaTypes[t.HeaderMediaType] {
+			return appErrors.NewValidation("header_media_type", "must be image, video or document")
+		}
+		if t.HeaderMediaURL == "" {
+			return appErrors.NewValidation("header_media_url", "is required when header_media_type is set")
+		}
+	}
+
+	return s.Repo.Create(t)
+}
+
+// Get fetches a single template
+func (s *WhatsAppTemplateService) Get(id int) (*model.WhatsAppTemplate, error) {
+	return s.Repo.GetByID(id)
+}
+
+// List returns all registered templates
+func (s *WhatsAppTemplateService) List() ([]model.WhatsAppTemplate, error) {
+	return s.Repo.List()
+}
+
+func highestParameter(body string) int {
+	highest := 0
+	for _, m := range whatsappParameter.FindAllStringSubmatch(body, -1) {
+		if n, err := strconv.Atoi(m[1]); err == nil && n > highest {
+			highest = n
+		}
+	}
+	return highest
+}
+
+// fillWhatsAppBody substitutes rendered parameters into an approved body. Templates
+// registered without a body are summarised by name so the stored content stays readable.
+func fillWhatsAppBody(t *model.WhatsAppTemplate, params []string) string {
+	if t.Body == "" {
+		return fmt.Sprintf("[%s/%s] %s", t.Name, t.Language, strings.Join(params, " | "))
+	}
+	return whatsappParameter.ReplaceAllStringFunc(t.Body, func(m string) string {
+		n, _ := strconv.Atoi(whatsappParameter.FindStringSubmatch(m)[1])
+		if n < 1 || n > len(params) {
+			return m
+		}
+		return params[n-1]
+	})
+}
+
+// validateWhatsAppCampaign checks that a campaign's parameters line up with its approved template
+func (s *CampaignService) validateWhatsAppCampaign(c *model.Campaign) error {
+	if c.Channel != "whatsapp" {
+		return appErrors.NewValidation("whatsapp_template_id", "can only be used with the whatsapp channel")
+	}
+	if s.WhatsAppTemplateRepo == nil {
+		return fmt.Errorf("whatsapp template registry is not configured")
+	}
+
+	t, err := s.WhatsAppTemplateRepo.GetByID(*c.WhatsAppTemplateID)
+	if err != nil {
+		return err
+	}
+	if len(c.WhatsAppParameters) != t.ParameterCount {
+		return appErrors.NewValidation("whatsapp_parameters", fmt.Sprintf("template %q expects %d parameter(s), got %d", t.Name, t.ParameterCount, len(c.WhatsAppParameters)))
+	}
+
+	for i, param := range c.WhatsAppParameters {
+		if strings.TrimSpace(param) == "" {
+			return appErrors.NewValidation("whatsapp_parameters", fmt.Sprintf("parameter %d is empty", i+1))
+		}
+		if problems := template.Validate(param, c.Variables); len(problems) > 0 {
+			return appErrors.NewValidation("whatsapp_parameters", fmt.Sprintf("parameter %d: %s", i+1, problems.Error()))
+		}
+	}
+	return nil
+}
+
+// renderWhatsApp maps customer data onto the campaign's template parameters
+func (s *CampaignService) renderWhatsApp(campaign *model.Campaign, data template.Data, link template.Linker) (string, []string, error) {
+	if s.WhatsAppTemplateRepo == nil {
+		return "", nil, fmt.Errorf("whatsapp template registry is not configured")
+	}
+	t, err := s.WhatsAppTemplateRepo.GetByID(*campaign.WhatsAppTemplateID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	params := make([]string, len(campaign.WhatsAppParameters))
+	for i, source := range campaign.WhatsAppParameters {
+		if params[i], err = template.RenderWithLinks(source, data, link); err != nil {
+			return "", nil, err
+		}
+	}
+	return fillWhatsAppBody(t, params), params, nil
+}
+
+// previewWhatsApp renders a template campaign and reports fields missing from any parameter
+func (s *CampaignService) previewWhatsApp(campaign *model.Campaign, data template.Data) (*PreviewResult, error) {
+	content, params, err := s.renderWhatsApp(campaign, data, s.linkerFor(0, nil))
+	if err != nil {
+		return nil, err
+	}
+
+	missing := []string{}
+	seen := map[string]bool{}
+	for _, source := range campaign.WhatsAppParameters {
+		tmpl, err := template.Parse(source)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range tmpl.Missing(data) {
+			if !seen[name] {
+				seen[name] = true
+				missing = append(missing, name)
+			}
+		}
+	}
+
+	return &PreviewResult{
+		Message:            content,
+		TemplateParameters: params,
+		MissingFields:      missing,
+		SMS:                sms.Analyze(content),
+	}, nil
+}
@@ -0,0 +1,76 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+type MockWhatsAppTemplateRepo struct {
+	templates map[int]*model.WhatsAppTemplate
+}
+
+func (m *MockWhatsAppTemplateRepo) Create(t *model.WhatsAppTemplate) error {
+	t.ID = len(m.templates) + 1
+	m.templates[t.ID] = t
+	return nil
+}
+
+func (m *MockWhatsAppTemplateRepo) GetByID(id int) (*model.WhatsAppTemplate, error) {
+	t, ok := m.templates[id]
+	if !ok {
+		return nil, appErrors.NewWhatsAppTemplateNotFound(id)
+	}
+	return t, nil
+}
+
+func (m *MockWhatsAppTemplateRepo) List() ([]model.WhatsAppTemplate, error) {
+	return nil, nil
+}
+
+func TestWhatsAppTemplateCampaign(t *testing.T) {
+	templates := &MockWhatsAppTemplateRepo{templates: map[int]*model.WhatsAppTemplate{
+		1: {ID: 1, Name: "promo_offer", Language: "en", Body: "Hello {{1}}, {{2}} is now {{3}} off!", ParameterCount: 3},
+	}}
+	svc := &service.CampaignService{
+		CampaignRepo:         &MockCampaignPaginationRepo{},
+		WhatsAppTemplateRepo: templates,
+	}
+
+	templateID := 1
+	input := service.CampaignInput{
+		Name:               "Winter WhatsApp",
+		Channel:            "whatsapp",
+		WhatsAppTemplateID: &templateID,
+		WhatsAppParameters: []string{"{first_name}", "{preferred_product}"},
+		Variables:          map[string]string{"discount": "20%"},
+	}
+
+	_, err := svc.CreateCampaign(input)
+	var validation *appErrors.ErrValidation
+	if !errors.As(err, &validation) || validation.Field != "whatsapp_parameters" {
+		t.Fatalf("expected parameter count validation error, got %v", err)
+	}
+
+	input.WhatsAppParameters = append(input.WhatsAppParameters, "{campaign.discount}")
+	campaign, err := svc.CreateCampaign(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rendered, err := svc.RenderMessage(campaign, &model.Customer{FirstName: "Alice", PreferredProduct: "Jacket"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "Hello Alice, Jacket is now 20% off!"; rendered != want {
+		t.Errorf("expected %q, got %q", want, rendered)
+	}
+
+	input.Channel = "sms"
+	if _, err := svc.CreateCampaign(input); !errors.As(err, &validation) {
+		t.Errorf("expected sms campaign with whatsapp template to be rejected, got %v", err)
+	}
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"log"
+
+	appErrors "github.com/unclebandit/smsleopard-backend/internal/errors"
 	"github.com/unclebandit/smsleopard-backend/internal/model"
 )
 
@@ -16,6 +18,10 @@ type Worker struct {
 	OutboundRepo OutboundRepository
 	JobChan      <-chan int
 	SendFunc     func(msg string) bool
+
+	// Campaigns renders messages stored without content, with the same engine as preview
+	// and SendCampaign; nil sends the stored content as is
+	Campaigns *CampaignService
 }
 
 // Constructor
@@ -36,8 +42,14 @@ func (w *Worker) Start() {
 			continue
 		}
 
-		// simulate sending
-		success := w.SendFunc("mock message")
+		if msg.RenderedContent == "" && w.Campaigns != nil {
+			if err := w.render(msg); err != nil {
+				log.Println("Failed to render message:", err)
+				continue
+			}
+		}
+
+		success := w.SendFunc(msg.RenderedContent)
 		if success {
 			msg.Status = "sent"
 		} else {
@@ -47,3 +59,19 @@ func (w *Worker) Start() {
 		w.OutboundRepo.Update(msg)
 	}
 }
+
+// render fills msg's content through CampaignService.RenderOutbound
+func (w *Worker) render(msg *model.OutboundMessage) error {
+	campaign, err := w.Campaigns.CampaignRepo.GetByID(msg.CampaignID)
+	if err != nil {
+		return err
+	}
+	customer, err := w.Campaigns.CustomerRepo.GetByID(msg.CustomerID)
+	if err != nil {
+		return err
+	}
+	if customer == nil {
+		return appErrors.NewCustomerNotFound(msg.CustomerID)
+	}
+	return w.Campaigns.RenderOutbound(campaign, customer, msg)
+}
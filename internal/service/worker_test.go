@@ -0,0 +1,44 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/service"
+)
+
+// MockWorkerOutboundRepo holds a single outbound message
+type MockWorkerOutboundRepo struct {
+	msg *model.OutboundMessage
+}
+
+func (m *MockWorkerOutboundRepo) GetByID(id int) (*model.OutboundMessage, error) { return m.msg, nil }
+func (m *MockWorkerOutboundRepo) Update(msg *model.OutboundMessage) error        { m.msg = msg; return nil }
+
+// MockWorkerCampaignRepo serves a campaign with a template for the worker to render
+type MockWorkerCampaignRepo struct {
+	MockCampaignPaginationRepo
+}
+
+func (m *MockWorkerCampaignRepo) GetByID(id int) (*model.Campaign, error) {
+	return &model.Campaign{ID: id, BaseTemplate: "Hi {first_name}"}, nil
+}
+
+func TestWorkerRendersWithCampaignTemplate(t *testing.T) {
+	repo := &MockWorkerOutboundRepo{msg: &model.OutboundMessage{ID: 1, CampaignID: 1, CustomerID: 1, Status: "pending"}}
+	jobs := make(chan int, 1)
+	jobs <- 1
+	close(jobs)
+
+	var sent string
+	worker := service.NewWorker(repo, jobs, func(msg string) bool {
+		sent = msg
+		return true
+	})
+	worker.Campaigns = &service.CampaignService{CampaignRepo: &MockWorkerCampaignRepo{}, CustomerRepo: &MockCustomerRepo{}}
+	worker.Start()
+
+	if sent != "Hi Alice" || repo.msg.RenderedContent != "Hi Alice" || repo.msg.Status != "sent" {
+		t.Errorf("expected the rendered template to be sent, got %q (%+v)", sent, repo.msg)
+	}
+}
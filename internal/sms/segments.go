@@ -0,0 +1,85 @@
+// internal/sms/segments.go
+package sms
+
+import "unicode/utf16"
+
+// Encoding is the character set an SMS will be sent with
+type Encoding string
+
+const (
+	GSM7 Encoding = "GSM-7"
+	UCS2 Encoding = "UCS-2"
+)
+
+// Per-segment capacity. Concatenated messages lose room to the UDH header.
+const (
+	gsm7Single    = 160
+	gsm7Multipart = 153
+	ucs2Single    = 70
+	ucs2Multipart = 67
+)
+
+// gsm7Basic is the GSM 03.38 default alphabet
+var gsm7Basic = map[rune]bool{}
+
+// gsm7Extension characters are sent as an escape plus a septet, costing two
+var gsm7Extension = map[rune]bool{}
+
+func init() {
+	for _, r := range "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+		"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà" {
+		gsm7Basic[r] = true
+	}
+	for _, r := range "^{}\\[~]|€\f" {
+		gsm7Extension[r] = true
+	}
+}
+
+// Info describes how a message will be billed
+type Info struct {
+	Encoding   Encoding `json:"encoding"`
+	Characters int      `json:"characters"`
+	Segments   int      `json:"segments"`
+}
+
+// DetectEncoding returns GSM7 when every character fits the GSM alphabet, UCS2 otherwise
+func DetectEncoding(text string) Encoding {
+	for _, r := range text {
+		if !gsm7Basic[r] && !gsm7Extension[r] {
+			return UCS2
+		}
+	}
+	return GSM7
+}
+
+// Analyze detects the encoding of text and counts the segments it will be split into
+func Analyze(text string) Info {
+	info := Info{
+		Encoding:   DetectEncoding(text),
+		Characters: len([]rune(text)),
+	}
+
+	var units, single, multipart int
+	if info.Encoding == GSM7 {
+		for _, r := range text {
+			units++
+			if gsm7Extension[r] {
+				units++
+			}
+		}
+		single, multipart = gsm7Single, gsm7Multipart
+	} else {
+		units = len(utf16.Encode([]rune(text)))
+		single, multipart = ucs2Single, ucs2Multipart
+	}
+
+	switch {
+	case units == 0:
+		info.Segments = 0
+	case units <= single:
+		info.Segments = 1
+	default:
+		info.Segments = (units + multipart - 1) / multipart
+	}
+	return info
+}
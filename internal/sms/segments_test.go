@@ -0,0 +1,38 @@
+package sms_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/unclebandit/smsleopard-backend/internal/sms"
+)
+
+func TestAnalyze(t *testing.T) {
+	cases := []struct {
+		name     string
+		text     string
+		encoding sms.Encoding
+		chars    int
+		segments int
+	}{
+		{"empty", "", sms.GSM7, 0, 0},
+		{"short gsm", "Hi Alice, check out Shoes!", sms.GSM7, 26, 1},
+		{"gsm limit", strings.Repeat("a", 160), sms.GSM7, 160, 1},
+		{"gsm concatenated", strings.Repeat("a", 161), sms.GSM7, 161, 2},
+		{"extension chars count double", strings.Repeat("€", 80), sms.GSM7, 80, 1},
+		{"extension chars overflow", strings.Repeat("€", 81), sms.GSM7, 81, 2},
+		{"ucs2 limit", strings.Repeat("ł", 70), sms.UCS2, 70, 1},
+		{"ucs2 concatenated", strings.Repeat("ł", 71), sms.UCS2, 71, 2},
+		{"emoji uses surrogate pairs", strings.Repeat("😀", 35), sms.UCS2, 35, 1},
+		{"emoji overflow", strings.Repeat("😀", 36), sms.UCS2, 36, 2},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := sms.Analyze(tc.text)
+			if info.Encoding != tc.encoding || info.Characters != tc.chars || info.Segments != tc.segments {
+				t.Errorf("expected %s/%d chars/%d segments, got %+v", tc.encoding, tc.chars, tc.segments, info)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+// internal/tagexpr/tagexpr.go
+package tagexpr
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// tagPattern is what a customer tag may look like, e.g. vip or churn-risk
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
+// ValidTag reports whether tag is a well-formed lowercase tag
+func ValidTag(tag string) bool {
+	return tagPattern.MatchString(tag)
+}
+
+// Expr is a parsed tag expression such as `vip AND NOT churn-risk`
+type Expr interface {
+	// Match reports whether a customer with the given tags is selected
+	Match(tags map[string]bool) bool
+	String() string
+}
+
+// Tag selects customers carrying the tag
+type Tag string
+
+// Not selects customers the inner expression does not
+type Not struct{ X Expr }
+
+// And selects customers matching both sides
+type And struct{ L, R Expr }
+
+// Or selects customers matching either side
+type Or struct{ L, R Expr }
+
+func (t Tag) Match(tags map[string]bool) bool { return tags[string(t)] }
+func (n Not) Match(tags map[string]bool) bool { return !n.X.Match(tags) }
+func (a And) Match(tags map[string]bool) bool { return a.L.Match(tags) && a.R.Match(tags) }
+func (o Or) Match(tags map[string]bool) bool  { return o.L.Match(tags) || o.R.Match(tags) }
+
+func (t Tag) String() string { return string(t) }
+func (n Not) String() string { return "NOT " + n.X.String() }
+func (a And) String() string { return "(" + a.L.String() + " AND " + a.R.String() + ")" }
+func (o Or) String() string  { return "(" + o.L.String() + " OR " + o.R.String() + ")" }
+
+// Tags lists the distinct tags an expression refers to, sorted
+func Tags(e Expr) []string {
+	seen := map[string]bool{}
+	var walk func(Expr)
+	walk = func(e Expr) {
+		switch e := e.(type) {
+		case Tag:
+			seen[string(e)] = true
+		case Not:
+			walk(e.X)
+		case And:
+			walk(e.L)
+			walk(e.R)
+		case Or:
+			walk(e.L)
+			walk(e.R)
+		}
+	}
+	walk(e)
+
+	tags := make([]string, 0, len(seen))
+	for t := range seen {
+		tags = append(tags, t)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// Error is a syntax error at a character offset of the expression
+type Error struct {
+	Offset  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("offset %d: %s", e.Offset, e.Message)
+}
+
+type token struct {
+	text   string
+	offset int
+}
+
+// tokenize splits an expression into words and parentheses
+func tokenize(s string) []token {
+	var tokens []token
+	start := -1
+	flush := func(end int) {
+		if start >= 0 {
+			tokens = append(tokens, token{s[start:end], start})
+			start = -1
+		}
+	}
+	for i, r := range s {
+		switch {
+		case r == '(' || r == ')':
+			flush(i)
+			tokens = append(tokens, token{string(r), i})
+		case r == ' ' || r == '\t' || r == '\n':
+			flush(i)
+		default:
+			if start < 0 {
+				start = i
+			}
+		}
+	}
+	flush(len(s))
+	return tokens
+}
+
+// Parse reads an expression of tags combined with AND, OR, NOT and parentheses. NOT binds
+// tightest and AND binds tighter than OR; keywords are case-insensitive.
+func Parse(s string) (Expr, error) {
+	p := &parser{tokens: tokenize(s), end: len(s)}
+	if len(p.tokens) == 0 {
+		return nil, &Error{Offset: 0, Message: "expression is empty"}
+	}
+	e, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := p.peek(); ok {
+		return nil, &Error{Offset: t.offset, Message: fmt.Sprintf("unexpected %q", t.text)}
+	}
+	return e, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	end    int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos], true
+	}
+	return token{offset: p.end}, false
+}
+
+func (p *parser) keyword(word string) bool {
+	t, ok := p.peek()
+	if ok && strings.EqualFold(t.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) or() (Expr, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = Or{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (Expr, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("AND") {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = And{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) unary() (Expr, error) {
+	if p.keyword("NOT") {
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return Not{x}, nil
+	}
+
+	t, ok := p.peek()
+	if !ok {
+		return nil, &Error{Offset: t.offset, Message: "expected a tag"}
+	}
+	switch {
+	case t.text == "(":
+		p.pos++
+		e, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		closing, ok := p.peek()
+		if !ok || closing.text != ")" {
+			return nil, &Error{Offset: closing.offset, Message: "missing )"}
+		}
+		p.pos++
+		return e, nil
+	case t.text == ")" || strings.EqualFold(t.text, "AND") || strings.EqualFold(t.text, "OR"):
+		return nil, &Error{Offset: t.offset, Message: fmt.Sprintf("expected a tag, got %q", t.text)}
+	case !ValidTag(t.text):
+		return nil, &Error{Offset: t.offset, Message: fmt.Sprintf("invalid tag %q", t.text)}
+	}
+	p.pos++
+	return Tag(t.text), nil
+}
@@ -0,0 +1,68 @@
+package tagexpr_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/unclebandit/smsleopard-backend/internal/tagexpr"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"vip", "vip"},
+		{"vip AND NOT churn-risk", "(vip AND NOT churn-risk)"},
+		{"vip or beta and not churn-risk", "(vip OR (beta AND NOT churn-risk))"},
+		{"(vip OR beta) AND NOT churn-risk", "((vip OR beta) AND NOT churn-risk)"},
+		{"NOT NOT vip", "NOT NOT vip"},
+		{"(vip)", "vip"},
+	}
+	for _, c := range cases {
+		e, err := tagexpr.Parse(c.in)
+		if err != nil {
+			t.Errorf("Parse(%q): unexpected error %v", c.in, err)
+			continue
+		}
+		if got := e.String(); got != c.want {
+			t.Errorf("Parse(%q) = %s, want %s", c.in, got, c.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		in     string
+		offset int
+	}{
+		{"", 0},
+		{"vip AND", 7},
+		{"AND vip", 0},
+		{"(vip OR beta", 12},
+		{"vip beta", 4},
+		{"vip)", 3},
+		{"VIP", 0},
+		{"vip AND NOT", 11},
+	}
+	for _, c := range cases {
+		_, err := tagexpr.Parse(c.in)
+		var syntax *tagexpr.Error
+		if !errors.As(err, &syntax) || syntax.Offset != c.offset {
+			t.Errorf("Parse(%q): expected error at offset %d, got %v", c.in, c.offset, err)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	e, _ := tagexpr.Parse("vip AND NOT churn-risk")
+	if !e.Match(map[string]bool{"vip": true, "beta": true}) {
+		t.Error("expected vip customer to match")
+	}
+	if e.Match(map[string]bool{"vip": true, "churn-risk": true}) {
+		t.Error("expected churn-risk customer not to match")
+	}
+	if got := tagexpr.Tags(e); len(got) != 2 || got[0] != "churn-risk" || got[1] != "vip" {
+		t.Errorf("unexpected tags %v", got)
+	}
+}
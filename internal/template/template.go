@@ -0,0 +1,603 @@
+// internal/template/template.go
+package template
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+)
+
+// Unknown is rendered in place of a placeholder whose value is missing or empty
+const Unknown = "[unknown]"
+
+// Data holds the values placeholders are resolved against, keyed by placeholder name
+type Data map[string]string
+
+// Error describes a template syntax problem at a character offset
+type Error struct {
+	Offset  int    `json:"offset"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("template error at offset %d: %s", e.Offset, e.Message)
+}
+
+// Errors lists every problem found in a template
+type Errors []*Error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Template is a parsed message template
+type Template struct {
+	nodes []node
+}
+
+// Linker returns the URL a {link:...} target is replaced with, e.g. a tracked short link
+type Linker func(target string) string
+
+type node interface {
+	render(b *strings.Builder, data Data, link Linker)
+}
+
+type textNode string
+
+func (n textNode) render(b *strings.Builder, data Data, link Linker) {
+	b.WriteString(string(n))
+}
+
+// linkNode is a {link:URL} tag
+type linkNode struct {
+	target string
+	offset int
+}
+
+func (n *linkNode) render(b *strings.Builder, data Data, link Linker) {
+	if link == nil {
+		b.WriteString(n.target)
+		return
+	}
+	b.WriteString(link(n.target))
+}
+
+// condition is the test of an {if} block
+type condition struct {
+	name  string
+	op    string // "", "==" or "!="
+	value string
+}
+
+// holds reports whether the condition is true for data. A bare name is true when
+// its value is non-blank.
+func (c condition) holds(data Data) bool {
+	value := data[c.name]
+	switch c.op {
+	case "==":
+		return value == c.value
+	case "!=":
+		return value != c.value
+	default:
+		return strings.TrimSpace(value) != ""
+	}
+}
+
+// ifNode renders one of two branches depending on its condition
+type ifNode struct {
+	cond      condition
+	offset    int
+	then      []node
+	otherwise []node
+	hasElse   bool
+}
+
+func (n *ifNode) render(b *strings.Builder, data Data, link Linker) {
+	for _, child := range n.branch(data) {
+		child.render(b, data, link)
+	}
+}
+
+func (n *ifNode) branch(data Data) []node {
+	if n.cond.holds(data) {
+		return n.then
+	}
+	return n.otherwise
+}
+
+type placeholderNode struct {
+	name    string
+	offset  int
+	filters []appliedFilter
+}
+
+type appliedFilter struct {
+	name string
+	arg  string
+}
+
+func (n *placeholderNode) render(b *strings.Builder, data Data, link Linker) {
+	value := data[n.name]
+	for _, f := range n.filters {
+		value = filters[f.name].apply(value, f.arg)
+	}
+	if value == "" {
+		value = Unknown
+	}
+	b.WriteString(value)
+}
+
+type filterDef struct {
+	needsArg bool
+	apply    func(value, arg string) string
+}
+
+// filters are the transformations available after a `|` in a placeholder
+var filters = map[string]filterDef{
+	"upper": {apply: func(v, _ string) string { return strings.ToUpper(v) }},
+	"lower": {apply: func(v, _ string) string { return strings.ToLower(v) }},
+	"title": {apply: func(v, _ string) string { return titleCase(v) }},
+	"trim":  {apply: func(v, _ string) string { return strings.TrimSpace(v) }},
+	"default": {needsArg: true, apply: func(v, arg string) string {
+		if strings.TrimSpace(v) == "" {
+			return arg
+		}
+		return v
+	}},
+}
+
+// Parse compiles src into a Template.
+//
+// Placeholders look like {first_name}, optionally followed by filters:
+// {first_name|upper}, {first_name|default:"friend"}, {location|title}.
+// Literal braces are written as {{ and }}.
+//
+// {link:https://shop.example/sale} inserts a URL that ExecuteWithLinks can replace
+// with a tracked short link.
+//
+// Sections can be made conditional with {if location}in {location}{/if} or
+// {if preferred_product == "Jacket"}...{else}...{/if}; != is also supported.
+//
+// Parsing does not stop at the first problem; the returned Errors lists all of them.
+func Parse(src string) (*Template, error) {
+	t, errs := parse(src)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return t, nil
+}
+
+// parse builds as much of the template as it can, collecting problems as it goes
+func parse(src string) (*Template, Errors) {
+	t := &Template{}
+	var errs Errors
+	var text strings.Builder
+
+	// open {if} blocks, innermost last; nodes are appended to the innermost branch
+	var blocks []*ifNode
+	add := func(n node) {
+		if len(blocks) == 0 {
+			t.nodes = append(t.nodes, n)
+			return
+		}
+		b := blocks[len(blocks)-1]
+		if b.hasElse {
+			b.otherwise = append(b.otherwise, n)
+		} else {
+			b.then = append(b.then, n)
+		}
+	}
+	flush := func() {
+		if text.Len() > 0 {
+			add(textNode(text.String()))
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(src); i++ {
+		switch src[i] {
+		case '{':
+			if i+1 < len(src) && src[i+1] == '{' {
+				text.WriteByte('{')
+				i++
+				continue
+			}
+			end := closingBrace(src[i:])
+			if end < 0 {
+				errs = append(errs, &Error{Offset: i, Message: "unclosed '{'"})
+				i = len(src)
+				continue
+			}
+			body := src[i+1 : i+end]
+			switch keyword := strings.TrimSpace(body); {
+			case keyword == "if" || strings.HasPrefix(keyword, "if "):
+				flush()
+				cond, err := parseCondition(strings.TrimSpace(keyword[2:]), i)
+				b := &ifNode{cond: cond, offset: i}
+				if err != nil {
+					// keep the block open, detached from the tree, so its {/if} still matches
+					errs = append(errs, err)
+				} else {
+					add(b)
+				}
+				blocks = append(blocks, b)
+			case keyword == "else":
+				if len(blocks) == 0 {
+					errs = append(errs, &Error{Offset: i, Message: "{else} without matching {if}"})
+					break
+				}
+				b := blocks[len(blocks)-1]
+				if b.hasElse {
+					errs = append(errs, &Error{Offset: i, Message: "duplicate {else} in {if} block"})
+					break
+				}
+				flush()
+				b.hasElse = true
+			case strings.HasPrefix(keyword, LinkPrefix):
+				l, err := parseLink(strings.TrimSpace(keyword[len(LinkPrefix):]), i)
+				if err != nil {
+					errs = append(errs, err)
+					break
+				}
+				flush()
+				add(l)
+			case keyword == "/if":
+				if len(blocks) == 0 {
+					errs = append(errs, &Error{Offset: i, Message: "{/if} without matching {if}"})
+					break
+				}
+				flush()
+				blocks = blocks[:len(blocks)-1]
+			default:
+				p, err := parsePlaceholder(body, i)
+				if err != nil {
+					errs = append(errs, err.(*Error))
+				} else {
+					flush()
+					add(p)
+				}
+			}
+			i += end
+		case '}':
+			if i+1 < len(src) && src[i+1] == '}' {
+				text.WriteByte('}')
+				i++
+				continue
+			}
+			errs = append(errs, &Error{Offset: i, Message: "unexpected '}' without matching '{'"})
+		default:
+			text.WriteByte(src[i])
+		}
+	}
+	flush()
+
+	for _, b := range blocks {
+		errs = append(errs, &Error{Offset: b.offset, Message: "{if} is never closed with {/if}"})
+	}
+
+	return t, errs
+}
+
+// parseCondition parses the expression of an {if ...} tag: a field name, optionally
+// compared to a quoted string with == or !=
+func parseCondition(expr string, offset int) (condition, *Error) {
+	if expr == "" {
+		return condition{}, &Error{Offset: offset, Message: "{if} requires a condition"}
+	}
+
+	var c condition
+	name, value := expr, ""
+	if i := strings.IndexAny(expr, "=!"); i >= 0 && i+1 < len(expr) && expr[i+1] == '=' {
+		c.op = expr[i : i+2]
+		name, value = expr[:i], strings.TrimSpace(expr[i+2:])
+	}
+
+	c.name = strings.TrimSpace(name)
+	if c.name == "" || !validName(c.name) {
+		return condition{}, &Error{Offset: offset, Message: fmt.Sprintf("invalid condition %q", expr)}
+	}
+	if c.op != "" {
+		if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+			return condition{}, &Error{Offset: offset, Message: fmt.Sprintf("%s must be followed by a quoted value", c.op)}
+		}
+		c.value = strings.ReplaceAll(value[1:len(value)-1], `\"`, `"`)
+	}
+	return c, nil
+}
+
+// parseLink checks the target of a {link:...} tag is an absolute http(s) URL
+func parseLink(target string, offset int) (*linkNode, *Error) {
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, &Error{Offset: offset, Message: fmt.Sprintf("invalid link %q, expected an http or https URL", target)}
+	}
+	return &linkNode{target: target, offset: offset}, nil
+}
+
+// parsePlaceholder parses the body of a {...} tag; offset is the position of the opening brace
+func parsePlaceholder(body string, offset int) (*placeholderNode, error) {
+	parts, err := splitFilters(body, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(parts[0])
+	if name == "" {
+		return nil, &Error{Offset: offset, Message: "empty placeholder"}
+	}
+	if !validName(name) {
+		return nil, &Error{Offset: offset, Message: fmt.Sprintf("invalid placeholder name %q", name)}
+	}
+
+	p := &placeholderNode{name: name, offset: offset}
+	for _, part := range parts[1:] {
+		f, err := parseFilter(strings.TrimSpace(part), offset)
+		if err != nil {
+			return nil, err
+		}
+		p.filters = append(p.filters, f)
+	}
+	return p, nil
+}
+
+// closingBrace returns the index of the '}' closing the tag at the start of s,
+// skipping braces inside quoted filter arguments, or -1 if there is none
+func closingBrace(s string) int {
+	inQuote := false
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if inQuote {
+				i++
+			}
+		case '"':
+			inQuote = !inQuote
+		case '}':
+			if !inQuote {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitFilters splits a placeholder body on '|' outside of quoted arguments
+func splitFilters(body string, offset int) ([]string, error) {
+	var parts []string
+	start := 0
+	inQuote := false
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '\\':
+			if inQuote {
+				i++
+			}
+		case '"':
+			inQuote = !inQuote
+		case '|':
+			if !inQuote {
+				parts = append(parts, body[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if inQuote {
+		return nil, &Error{Offset: offset, Message: "unterminated quoted argument"}
+	}
+	return append(parts, body[start:]), nil
+}
+
+func parseFilter(spec string, offset int) (appliedFilter, error) {
+	name, arg, hasArg := strings.Cut(spec, ":")
+	name = strings.TrimSpace(name)
+
+	def, ok := filters[name]
+	if !ok {
+		return appliedFilter{}, &Error{Offset: offset, Message: fmt.Sprintf("unknown filter %q", name)}
+	}
+	if !def.needsArg {
+		if hasArg {
+			return appliedFilter{}, &Error{Offset: offset, Message: fmt.Sprintf("filter %q takes no argument", name)}
+		}
+		return appliedFilter{name: name}, nil
+	}
+
+	arg = strings.TrimSpace(arg)
+	if !hasArg || len(arg) < 2 || arg[0] != '"' || arg[len(arg)-1] != '"' {
+		return appliedFilter{}, &Error{Offset: offset, Message: fmt.Sprintf("filter %q requires a quoted argument", name)}
+	}
+	arg = strings.ReplaceAll(arg[1:len(arg)-1], `\"`, `"`)
+	return appliedFilter{name: name, arg: arg}, nil
+}
+
+func validName(name string) bool {
+	for _, r := range name {
+		if !(r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// KnownFields are the customer placeholders a template may reference
+var KnownFields = []string{"first_name", "last_name", "location", "preferred_product"}
+
+// Validate parses src and additionally reports placeholders that do not name a
+// known field, a custom attribute or one of the campaign's variables.
+// It returns nil when the template is safe to send.
+func Validate(src string, variables map[string]string) Errors {
+	t, errs := parse(src)
+
+	walk(t.nodes, nil, func(name string, offset int) {
+		if key, ok := strings.CutPrefix(name, CampaignPrefix); ok {
+			if _, defined := variables[key]; !defined {
+				errs = append(errs, &Error{Offset: offset, Message: fmt.Sprintf("undefined campaign variable %q", key)})
+			}
+			return
+		}
+		if !isKnownField(name) {
+			errs = append(errs, &Error{Offset: offset, Message: fmt.Sprintf("unknown placeholder %q", name)})
+		}
+	})
+
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Offset < errs[j].Offset })
+	return errs
+}
+
+func isKnownField(name string) bool {
+	if key, ok := strings.CutPrefix(name, AttributePrefix); ok {
+		return key != "" && !strings.Contains(key, ".")
+	}
+	for _, f := range KnownFields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Execute renders the template against data. Missing or empty values become Unknown.
+// {link:...} tags render their target unchanged.
+func (t *Template) Execute(data Data) string {
+	return t.ExecuteWithLinks(data, nil)
+}
+
+// ExecuteWithLinks renders the template, replacing each {link:...} target with link(target)
+func (t *Template) ExecuteWithLinks(data Data, link Linker) string {
+	var b strings.Builder
+	for _, n := range t.nodes {
+		n.render(&b, data, link)
+	}
+	return b.String()
+}
+
+// Links returns the {link:...} targets used by the template, in order of appearance
+func (t *Template) Links() []string {
+	return links(t.nodes, []string{})
+}
+
+func links(nodes []node, targets []string) []string {
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case *linkNode:
+			targets = append(targets, n.target)
+		case *ifNode:
+			targets = links(n.otherwise, links(n.then, targets))
+		}
+	}
+	return targets
+}
+
+// walk calls visit for every placeholder and {if} condition name, in order of appearance.
+// With data set, only the branches taken for that data are visited.
+func walk(nodes []node, data Data, visit func(name string, offset int)) {
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case *placeholderNode:
+			visit(n.name, n.offset)
+		case *ifNode:
+			if data == nil {
+				visit(n.cond.name, n.offset)
+				walk(n.then, nil, visit)
+				walk(n.otherwise, nil, visit)
+			} else {
+				walk(n.branch(data), data, visit)
+			}
+		}
+	}
+}
+
+// Placeholders returns the placeholder names used by the template, including those
+// only tested by {if} blocks, in order of appearance
+func (t *Template) Placeholders() []string {
+	names := []string{}
+	walk(t.nodes, nil, func(name string, _ int) {
+		names = append(names, name)
+	})
+	return names
+}
+
+// Missing returns the placeholders that would render as Unknown for data, without
+// duplicates. Placeholders inside branches not taken are not reported.
+func (t *Template) Missing(data Data) []string {
+	missing := []string{}
+	seen := map[string]bool{}
+	walk(t.nodes, data, func(name string, _ int) {
+		if seen[name] || data[name] != "" {
+			return
+		}
+		seen[name] = true
+		missing = append(missing, name)
+	})
+	return missing
+}
+
+// Render parses and executes src in one step
+func Render(src string, data Data) (string, error) {
+	return RenderWithLinks(src, data, nil)
+}
+
+// RenderWithLinks parses and executes src, replacing link targets with link(target)
+func RenderWithLinks(src string, data Data, link Linker) (string, error) {
+	t, err := Parse(src)
+	if err != nil {
+		return "", err
+	}
+	return t.ExecuteWithLinks(data, link), nil
+}
+
+// AttributePrefix namespaces custom customer attributes, e.g. {attr.loyalty_tier}
+const AttributePrefix = "attr."
+
+// LinkPrefix starts a tracked link tag, e.g. {link:https://shop.example/sale}
+const LinkPrefix = "link:"
+
+// CampaignPrefix namespaces per-campaign variables, e.g. {campaign.discount}
+const CampaignPrefix = "campaign."
+
+// With returns a copy of d extended with values under the given prefix
+func (d Data) With(prefix string, values map[string]string) Data {
+	out := make(Data, len(d)+len(values))
+	for k, v := range d {
+		out[k] = v
+	}
+	for k, v := range values {
+		out[prefix+k] = v
+	}
+	return out
+}
+
+// CustomerData exposes a customer's fields and custom attributes as template data
+func CustomerData(c *model.Customer) Data {
+	data := Data{
+		"first_name":        c.FirstName,
+		"last_name":         c.LastName,
+		"location":          c.Location,
+		"preferred_product": c.PreferredProduct,
+	}
+	return data.With(AttributePrefix, c.Attributes)
+}
+
+func titleCase(s string) string {
+	runes := []rune(strings.ToLower(s))
+	startOfWord := true
+	for i, r := range runes {
+		if unicode.IsSpace(r) || r == '-' {
+			startOfWord = true
+			continue
+		}
+		if startOfWord {
+			runes[i] = unicode.ToUpper(r)
+			startOfWord = false
+		}
+	}
+	return string(runes)
+}
@@ -0,0 +1,221 @@
+package template_test
+
+import (
+	"testing"
+
+	"github.com/unclebandit/smsleopard-backend/internal/model"
+	"github.com/unclebandit/smsleopard-backend/internal/template"
+)
+
+func TestRenderPlaceholdersAndFilters(t *testing.T) {
+	customer := &model.Customer{
+		FirstName:        "alice",
+		LastName:         "Mwangi",
+		Location:         "nairobi west",
+		PreferredProduct: "",
+	}
+
+	cases := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"plain", "Hi {first_name} {last_name}", "Hi alice Mwangi"},
+		{"upper", "Hi {first_name|upper}", "Hi ALICE"},
+		{"title", "See you in {location|title}", "See you in Nairobi West"},
+		{"missing falls back to unknown", "Get {preferred_product}", "Get [unknown]"},
+		{"default filter", "Get {preferred_product|default:\"a gift\"}", "Get a gift"},
+		{"default then upper", "{preferred_product|default:\"gift\"|upper}", "GIFT"},
+		{"quoted argument with brace", "{preferred_product|default:\"}\"}", "}"},
+		{"escaped braces", "{{first_name}} is {first_name}", "{first_name} is alice"},
+		{"unknown name", "Hi {nickname}", "Hi [unknown]"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := template.Render(tc.template, template.CustomerData(customer))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		template string
+		offset   int
+	}{
+		{"Hi {first_name", 3},
+		{"Hi first_name}", 13},
+		{"Hi {}", 3},
+		{"Hi {first_name|shout}", 3},
+		{"Hi {first_name|default}", 3},
+		{"Hi {first_name|upper:\"x\"}", 3},
+	}
+
+	for _, tc := range cases {
+		_, err := template.Parse(tc.template)
+		if err == nil {
+			t.Errorf("expected error for %q", tc.template)
+			continue
+		}
+		errs, ok := err.(template.Errors)
+		if !ok || len(errs) != 1 {
+			t.Fatalf("%q: expected a single template.Errors entry, got %#v", tc.template, err)
+		}
+		if errs[0].Offset != tc.offset {
+			t.Errorf("%q: expected offset %d, got %d", tc.template, tc.offset, errs[0].Offset)
+		}
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	errs := template.Validate("Hi {firstname}, {first_name|upper} } {location|shout}", nil)
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 problems, got %d: %v", len(errs), errs)
+	}
+
+	wantOffsets := []int{3, 35, 37}
+	for i, want := range wantOffsets {
+		if errs[i].Offset != want {
+			t.Errorf("problem %d: expected offset %d, got %d (%s)", i, want, errs[i].Offset, errs[i].Message)
+		}
+	}
+
+	if errs := template.Validate("Hi {first_name|default:\"friend\"}, enjoy {preferred_product}!", nil); errs != nil {
+		t.Errorf("expected valid template, got %v", errs)
+	}
+}
+
+func TestAttributePlaceholders(t *testing.T) {
+	customer := &model.Customer{
+		FirstName:  "Alice",
+		Attributes: map[string]string{"loyalty_tier": "gold"},
+	}
+	data := template.CustomerData(customer)
+
+	tmpl, err := template.Parse("{first_name}, your {attr.loyalty_tier|upper} perks expire {attr.expiry}")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, want := tmpl.Execute(data), "Alice, your GOLD perks expire [unknown]"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	missing := tmpl.Missing(data)
+	if len(missing) != 1 || missing[0] != "attr.expiry" {
+		t.Errorf("expected attr.expiry to be missing, got %v", missing)
+	}
+
+	if errs := template.Validate("{attr.loyalty_tier} {attr.} {attr.a.b}", nil); len(errs) != 2 {
+		t.Errorf("expected 2 problems for malformed attribute names, got %v", errs)
+	}
+}
+
+func TestCampaignVariables(t *testing.T) {
+	variables := map[string]string{"discount": "20%", "promo_end": "Friday"}
+	data := template.CustomerData(&model.Customer{FirstName: "Bob"}).With(template.CampaignPrefix, variables)
+
+	got, err := template.Render("Hi {first_name}, take {campaign.discount} off until {campaign.promo_end}", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "Hi Bob, take 20% off until Friday"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	errs := template.Validate("{campaign.discount} {campaign.code}", variables)
+	if len(errs) != 1 || errs[0].Offset != 20 {
+		t.Errorf("expected only campaign.code to be reported, got %v", errs)
+	}
+}
+
+func TestConditionalBlocks(t *testing.T) {
+	src := `Hi {first_name}{if location} in {location}{/if}! {if preferred_product == "Jacket"}Stay warm.{else}See what's new.{/if}`
+	tmpl, err := template.Parse(src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		data template.Data
+		want string
+	}{
+		{template.Data{"first_name": "Alice", "location": "Nairobi", "preferred_product": "Jacket"}, "Hi Alice in Nairobi! Stay warm."},
+		{template.Data{"first_name": "Alice", "preferred_product": "Shoes"}, "Hi Alice! See what's new."},
+		{template.Data{"first_name": "Alice", "location": "  "}, "Hi Alice! See what's new."},
+	}
+	for _, tc := range cases {
+		if got := tmpl.Execute(tc.data); got != tc.want {
+			t.Errorf("expected %q, got %q", tc.want, got)
+		}
+	}
+
+	// a placeholder inside a branch that is not taken is not missing
+	if missing := tmpl.Missing(template.Data{"first_name": "Alice"}); len(missing) != 0 {
+		t.Errorf("expected no missing fields, got %v", missing)
+	}
+
+	nested, err := template.Render(`{if location}{if location != "Nairobi"}Outside{else}Inside{/if} Nairobi{/if}`, template.Data{"location": "Mombasa"})
+	if err != nil || nested != "Outside Nairobi" {
+		t.Errorf("expected nested blocks to render %q, got %q (%v)", "Outside Nairobi", nested, err)
+	}
+}
+
+func TestConditionalParseErrors(t *testing.T) {
+	cases := []struct {
+		src    string
+		offset int
+	}{
+		{"{if location}in {location}", 0},
+		{"Hi{/if}", 2},
+		{"Hi{else}", 2},
+		{"{if}x{/if}", 0},
+		{"{if location == Nairobi}x{/if}", 0},
+		{"{if location}a{else}b{else}c{/if}", 21},
+	}
+	for _, tc := range cases {
+		_, err := template.Parse(tc.src)
+		errs, ok := err.(template.Errors)
+		if !ok || len(errs) != 1 || errs[0].Offset != tc.offset {
+			t.Errorf("%q: expected one error at offset %d, got %v", tc.src, tc.offset, err)
+		}
+	}
+
+	errs := template.Validate("{if loc}x{/if}{if campaign.code}y{/if}", nil)
+	if len(errs) != 2 || errs[0].Offset != 0 || errs[1].Offset != 14 {
+		t.Errorf("expected unknown condition fields to be reported, got %v", errs)
+	}
+}
+
+func TestLinks(t *testing.T) {
+	tmpl, err := template.Parse("Hi {first_name}, shop now: {link:https://shop.example/sale?src=sms}")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if links := tmpl.Links(); len(links) != 1 || links[0] != "https://shop.example/sale?src=sms" {
+		t.Errorf("expected one link target, got %v", links)
+	}
+
+	data := template.Data{"first_name": "Alice"}
+	if got, want := tmpl.Execute(data), "Hi Alice, shop now: https://shop.example/sale?src=sms"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	short := func(target string) string { return "https://sl.example/l/abc1234" }
+	if got, want := tmpl.ExecuteWithLinks(data, short), "Hi Alice, shop now: https://sl.example/l/abc1234"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	for _, src := range []string{"{link:}", "{link:shop.example/sale}", "{link:ftp://shop.example}"} {
+		if _, err := template.Parse(src); err == nil {
+			t.Errorf("%q: expected invalid link to be rejected", src)
+		}
+	}
+}
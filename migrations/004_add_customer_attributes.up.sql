@@ -0,0 +1,4 @@
+-- 004_add_customer_attributes.sql
+-- Free-form personalization fields usable as {attr.<key>} template placeholders
+
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}'::jsonb;
@@ -0,0 +1,4 @@
+-- 005_add_campaign_variables.sql
+-- Per-campaign dynamic variables rendered as {campaign.<key>}
+
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS variables JSONB NOT NULL DEFAULT '{}'::jsonb;
@@ -0,0 +1,4 @@
+-- 006_add_outbound_message_segments.sql
+-- Number of SMS segments the rendered message is billed as
+
+ALTER TABLE outbound_messages ADD COLUMN IF NOT EXISTS segments INT NOT NULL DEFAULT 0;
@@ -0,0 +1,21 @@
+-- 007_create_whatsapp_templates.sql
+-- Registry of pre-approved WhatsApp templates and their use by campaigns
+
+CREATE TABLE IF NOT EXISTS whatsapp_templates (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    language TEXT NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    parameter_count INT NOT NULL DEFAULT 0 CHECK (parameter_count >= 0),
+    header_media_type TEXT CHECK (header_media_type IN ('image', 'video', 'document')),
+    header_media_url TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (name, language)
+);
+
+-- Campaigns either render base_template or reference an approved template
+ALTER TABLE campaigns ALTER COLUMN base_template SET DEFAULT '';
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS whatsapp_template_id INT REFERENCES whatsapp_templates(id);
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS whatsapp_parameters JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+ALTER TABLE outbound_messages ADD COLUMN IF NOT EXISTS template_parameters JSONB;
@@ -0,0 +1,7 @@
+-- 008_add_language_variants.sql
+-- Customer language preference and per-language campaign templates
+
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS language TEXT;
+
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS template_variants JSONB NOT NULL DEFAULT '{}'::jsonb;
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS fallback_language TEXT;
@@ -0,0 +1,7 @@
+-- 009_add_campaign_variants.sql
+-- A/B/n message variants with percentage splits, and the variant each message received
+
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS variants JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+ALTER TABLE outbound_messages ADD COLUMN IF NOT EXISTS variant TEXT;
+CREATE INDEX IF NOT EXISTS idx_outbound_messages_campaign_variant ON outbound_messages(campaign_id, variant);
@@ -0,0 +1,21 @@
+-- 010_create_short_links.sql
+-- Per-message tracked short links and the clicks recorded against them
+
+CREATE TABLE IF NOT EXISTS short_links (
+    id SERIAL PRIMARY KEY,
+    code TEXT NOT NULL UNIQUE,
+    outbound_message_id INT NOT NULL REFERENCES outbound_messages(id) ON DELETE CASCADE,
+    target_url TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (outbound_message_id, target_url)
+);
+
+CREATE TABLE IF NOT EXISTS link_clicks (
+    id SERIAL PRIMARY KEY,
+    short_link_id INT NOT NULL REFERENCES short_links(id) ON DELETE CASCADE,
+    user_agent TEXT,
+    ip_address TEXT,
+    clicked_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_link_clicks_short_link ON link_clicks(short_link_id);
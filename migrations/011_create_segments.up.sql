@@ -0,0 +1,12 @@
+-- 011_create_segments.sql
+-- Saved audience segments; rules are compiled to a WHERE clause over customers
+
+CREATE TABLE IF NOT EXISTS segments (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    rules JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_customers_location ON customers(location);
+CREATE INDEX IF NOT EXISTS idx_customers_preferred_product ON customers(preferred_product);
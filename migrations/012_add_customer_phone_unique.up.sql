@@ -0,0 +1,4 @@
+-- 012_add_customer_phone_unique.sql
+-- Enforce one customer per phone number; duplicates must be merged before running this
+
+CREATE UNIQUE INDEX IF NOT EXISTS customers_phone_key ON customers(phone);
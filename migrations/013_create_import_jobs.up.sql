@@ -0,0 +1,27 @@
+-- 013_create_import_jobs.sql
+-- Bulk CSV customer imports and the per-row outcome report
+
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id SERIAL PRIMARY KEY,
+    filename TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'running', -- running, completed, failed
+    total_rows INT NOT NULL DEFAULT 0,
+    accepted INT NOT NULL DEFAULT 0,
+    updated INT NOT NULL DEFAULT 0,
+    rejected INT NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS import_job_rows (
+    id SERIAL PRIMARY KEY,
+    job_id INT NOT NULL REFERENCES import_jobs(id) ON DELETE CASCADE,
+    row_number INT NOT NULL,
+    phone TEXT,
+    outcome TEXT NOT NULL, -- accepted, updated, rejected
+    reason TEXT,
+    customer_id INT REFERENCES customers(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_job_rows_job_outcome ON import_job_rows(job_id, outcome, row_number);
@@ -0,0 +1,11 @@
+-- 014_create_suppressions.sql
+-- Customers who opted out of a channel ('all' covers every channel)
+
+CREATE TABLE IF NOT EXISTS suppressions (
+    id SERIAL PRIMARY KEY,
+    customer_id INT NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    channel TEXT NOT NULL, -- sms, whatsapp or all
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (customer_id, channel)
+);
@@ -0,0 +1,15 @@
+-- 015_create_consents.sql
+-- Marketing consent per customer and channel. Revoking keeps the row as proof of
+-- what was held when; at most one grant per customer and channel is active.
+
+CREATE TABLE IF NOT EXISTS consents (
+    id SERIAL PRIMARY KEY,
+    customer_id INT NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    channel TEXT NOT NULL, -- sms or whatsapp
+    source TEXT NOT NULL, -- where consent was captured, e.g. web_form, ussd, import
+    granted_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS consents_active_key ON consents (customer_id, channel) WHERE revoked_at IS NULL;
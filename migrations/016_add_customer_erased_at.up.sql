@@ -0,0 +1,5 @@
+-- 016_add_customer_erased_at.sql
+-- Set when a customer's personal data is erased on request; the row is kept,
+-- anonymized, so campaign stats still add up
+
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS erased_at TIMESTAMP;
@@ -0,0 +1,14 @@
+-- 017_add_frequency_capping.sql
+-- What a campaign does with customers who already got the maximum number of messages
+-- in the cap window ('defer' or 'skip'), when a message was sent, and when a deferred
+-- message may be retried
+
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS frequency_cap_policy TEXT NOT NULL DEFAULT 'defer';
+
+ALTER TABLE outbound_messages ADD COLUMN IF NOT EXISTS sent_at TIMESTAMP;
+ALTER TABLE outbound_messages ADD COLUMN IF NOT EXISTS not_before TIMESTAMP;
+
+UPDATE outbound_messages SET sent_at = updated_at WHERE status = 'sent' AND sent_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_outbound_messages_customer_sent ON outbound_messages (customer_id, sent_at);
+CREATE INDEX IF NOT EXISTS idx_outbound_messages_deferred ON outbound_messages (not_before) WHERE status = 'deferred';
@@ -0,0 +1,9 @@
+-- 018_add_quiet_hours.sql
+-- Customer timezones and per-campaign quiet hours overriding the global default,
+-- e.g. {"start": "21:00", "end": "08:00"}
+
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS timezone TEXT;
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS quiet_hours JSONB;
+
+DROP INDEX IF EXISTS idx_outbound_messages_deferred;
+CREATE INDEX IF NOT EXISTS idx_outbound_messages_not_before ON outbound_messages (not_before) WHERE status IN ('deferred', 'held');
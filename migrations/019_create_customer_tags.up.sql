@@ -0,0 +1,11 @@
+-- 019_create_customer_tags.sql
+-- Free-form customer labels such as vip or churn-risk, used for tag-expression targeting
+
+CREATE TABLE IF NOT EXISTS customer_tags (
+    customer_id INT NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (customer_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_tags_tag ON customer_tags (tag);
@@ -0,0 +1,6 @@
+-- 020_add_campaign_pause_cancel.sql
+-- Campaigns can be paused and cancelled; their unsent messages become 'paused' or 'cancelled'
+
+ALTER TABLE campaigns DROP CONSTRAINT IF EXISTS campaigns_status_check;
+ALTER TABLE campaigns ADD CONSTRAINT campaigns_status_check
+    CHECK (status IN ('draft', 'scheduled', 'sending', 'paused', 'sent', 'failed', 'cancelled'));
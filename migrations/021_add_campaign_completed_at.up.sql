@@ -0,0 +1,6 @@
+-- 021_add_campaign_completed_at.sql
+-- When a campaign finished sending, i.e. moved from 'sending' to 'sent' or 'failed'
+
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_outbound_messages_campaign_status ON outbound_messages (campaign_id, status);
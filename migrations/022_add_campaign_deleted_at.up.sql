@@ -0,0 +1,4 @@
+-- 022_add_campaign_deleted_at.sql
+-- Soft deletion of draft campaigns; deleted campaigns are hidden from every query
+
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,8 @@
+-- 023_add_campaign_audience_and_source.sql
+-- Who a campaign targets, e.g. {"segment_id": 3} or {"tag_expression": "vip AND NOT churn-risk"},
+-- and the campaign it was cloned from
+
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS audience JSONB;
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS source_campaign_id INT REFERENCES campaigns(id);
+
+CREATE INDEX IF NOT EXISTS idx_campaigns_source_campaign_id ON campaigns (source_campaign_id);
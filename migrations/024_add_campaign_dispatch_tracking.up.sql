@@ -0,0 +1,8 @@
+-- 024_add_campaign_dispatch_tracking.sql
+-- The scheduler stamps dispatch_started_at when it claims a due campaign and dispatched_at once
+-- every message is queued; a campaign with the first but not the second was interrupted
+
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS dispatch_started_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE campaigns ADD COLUMN IF NOT EXISTS dispatched_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_campaigns_due ON campaigns (scheduled_at) WHERE status = 'scheduled';
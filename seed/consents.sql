@@ -0,0 +1,8 @@
+-- SMS consent for every sample customer, WhatsApp for the first half
+INSERT INTO consents (customer_id, channel, source)
+SELECT id, 'sms', 'seed' FROM customers
+ON CONFLICT DO NOTHING;
+
+INSERT INTO consents (customer_id, channel, source)
+SELECT id, 'whatsapp', 'seed' FROM customers WHERE phone <= '+254710000005'
+ON CONFLICT DO NOTHING;
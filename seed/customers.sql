@@ -1,13 +1,13 @@
 -- Insert 10 sample customers
 INSERT INTO customers (phone, first_name, last_name, location, preferred_product)
 VALUES
-('0710000001', 'Alice', 'Mwangi', 'Nairobi', 'Running Shoes'),
-('0710000002', 'Bob', 'Otieno', 'Mombasa', 'Winter Jacket'),
-('0710000003', 'Charlie', 'Kamau', 'Nakuru', 'T-Shirt'),
-('0710000004', 'Diana', 'Achieng', 'Kisumu', 'Sneakers'),
-('0710000005', 'Evan', 'Waweru', 'Eldoret', 'Hoodie'),
-('0710000006', 'Faith', 'Njoki', 'Thika', 'Cap'),
-('0710000007', 'George', 'Ouma', 'Naivasha', 'Backpack'),
-('0710000008', 'Hannah', 'Chebet', 'Kitale', 'Jeans'),
-('0710000009', 'Ian', 'Kiptoo', 'Nakuru', 'Socks'),
-('0710000010', 'Jane', 'Wanjiku', 'Machakos', 'Jacket');
+('+254710000001', 'Alice', 'Mwangi', 'Nairobi', 'Running Shoes'),
+('+254710000002', 'Bob', 'Otieno', 'Mombasa', 'Winter Jacket'),
+('+254710000003', 'Charlie', 'Kamau', 'Nakuru', 'T-Shirt'),
+('+254710000004', 'Diana', 'Achieng', 'Kisumu', 'Sneakers'),
+('+254710000005', 'Evan', 'Waweru', 'Eldoret', 'Hoodie'),
+('+254710000006', 'Faith', 'Njoki', 'Thika', 'Cap'),
+('+254710000007', 'George', 'Ouma', 'Naivasha', 'Backpack'),
+('+254710000008', 'Hannah', 'Chebet', 'Kitale', 'Jeans'),
+('+254710000009', 'Ian', 'Kiptoo', 'Nakuru', 'Socks'),
+('+254710000010', 'Jane', 'Wanjiku', 'Machakos', 'Jacket');